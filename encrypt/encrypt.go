@@ -0,0 +1,202 @@
+/**
+ * @Time: 2026/10/19 18:02
+ * @Author: agent
+ */
+
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+const (
+
+	/** AES-128-GCM，16 字节密钥 */
+	AES128GCM = "A128GCM"
+
+	/** AES-256-GCM，32 字节密钥 */
+	AES256GCM = "A256GCM"
+
+	/** SM4-GCM，16 字节密钥 */
+	SM4GCM = "SM4GCM"
+
+	/** SM4-CBC + HMAC-SM3，先加密后认证，16 字节密钥 */
+	SM4CBC = "SM4CBC"
+)
+
+var (
+	ErrDecrypt              = errors.New("解密失败")
+	ErrUnsupportedAlgorithm = errors.New("不支持的加密算法")
+)
+
+// KeySize 算法要求的密钥长度，不支持的算法返回 0
+func KeySize(algorithm string) int {
+	switch strings.ToUpper(algorithm) {
+	case AES128GCM, SM4GCM, SM4CBC:
+		return 16
+	case AES256GCM:
+		return 32
+	}
+	return 0
+}
+
+// GenerateKey 生成算法对应长度的随机密钥
+func GenerateKey(algorithm string) ([]byte, error) {
+	size := KeySize(algorithm)
+	if size == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewAEAD 创建算法对应的认证加密，每次加密都必须使用新的随机 nonce
+func NewAEAD(algorithm string, key []byte) (cipher.AEAD, error) {
+	algorithm = strings.ToUpper(algorithm)
+	size := KeySize(algorithm)
+	if size == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(key) != size {
+		return nil, KeySizeError(len(key))
+	}
+	switch algorithm {
+	case AES128GCM, AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case SM4GCM:
+		block, err := NewSM4(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	return newCBCHmac(key)
+}
+
+// Seal 使用随机 nonce 加密，返回 nonce 和密文，additionalData 参与认证但不加密
+func Seal(algorithm string, key, plaintext, additionalData []byte) ([]byte, []byte, error) {
+	aead, err := NewAEAD(algorithm, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// Open 解密并校验 Seal 的结果，密文或 additionalData 被篡改时返回 ErrDecrypt
+func Open(algorithm string, key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := NewAEAD(algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// cbcHmac SM4-CBC + HMAC-SM3 的认证加密，nonce 即 CBC 的 iv
+// 加密和认证的密钥由原始密钥经 HMAC-SM3 派生，认证覆盖 additionalData、iv、密文和 additionalData 的长度
+type cbcHmac struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+const cbcHmacTagSize = 32
+
+func newCBCHmac(key []byte) (cipher.AEAD, error) {
+	encKey := deriveKey(key, "sm4-cbc-enc")[:SM4KeySize]
+	block, err := NewSM4(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &cbcHmac{block: block, macKey: deriveKey(key, "sm4-cbc-mac")}, nil
+}
+
+func deriveKey(key []byte, label string) []byte {
+	mac := hmac.New(sign.NewSM3, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+func (c *cbcHmac) NonceSize() int {
+	return SM4BlockSize
+}
+
+func (c *cbcHmac) Overhead() int {
+	return SM4BlockSize + cbcHmacTagSize
+}
+
+func (c *cbcHmac) tag(nonce, ciphertext, additionalData []byte) []byte {
+	mac := hmac.New(sign.NewSM3, c.macKey)
+	mac.Write(additionalData)
+	mac.Write(nonce)
+	mac.Write(ciphertext)
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(additionalData))*8)
+	mac.Write(n[:])
+	return mac.Sum(nil)
+}
+
+func (c *cbcHmac) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != SM4BlockSize {
+		panic("sm4-cbc: nonce 长度错误")
+	}
+	// PKCS#7 填充
+	padding := SM4BlockSize - len(plaintext)%SM4BlockSize
+	data := make([]byte, len(plaintext)+padding, len(plaintext)+padding+cbcHmacTagSize)
+	copy(data, plaintext)
+	for i := len(plaintext); i < len(data); i++ {
+		data[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(data, data)
+	data = append(data, c.tag(nonce, data, additionalData)...)
+	return append(dst, data...)
+}
+
+func (c *cbcHmac) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != SM4BlockSize || len(ciphertext) < c.Overhead() || (len(ciphertext)-cbcHmacTagSize)%SM4BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+	data, tag := ciphertext[:len(ciphertext)-cbcHmacTagSize], ciphertext[len(ciphertext)-cbcHmacTagSize:]
+	// 先校验再解密，避免填充预言攻击
+	if !hmac.Equal(tag, c.tag(nonce, data, additionalData)) {
+		return nil, ErrDecrypt
+	}
+	plaintext := make([]byte, len(data))
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(plaintext, data)
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > SM4BlockSize {
+		return nil, ErrDecrypt
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if subtle.ConstantTimeByteEq(b, byte(padding)) != 1 {
+			return nil, ErrDecrypt
+		}
+	}
+	return append(dst, plaintext[:len(plaintext)-padding]...), nil
+}
@@ -0,0 +1,102 @@
+/**
+ * @Time: 2026/10/19 18:09
+ * @Author: agent
+ */
+
+package encrypt
+
+import (
+	"testing"
+)
+
+var algorithms = []string{AES128GCM, AES256GCM, SM4GCM, SM4CBC}
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range algorithms {
+		key, err := GenerateKey(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 覆盖空明文、不足一个分组和整分组的明文
+		for _, plaintext := range []string{"", "13800138000", "0123456789abcdef", "110101199003071234"} {
+			nonce, ciphertext, err := Seal(alg, key, []byte(plaintext), []byte("ad"))
+			if err != nil {
+				t.Fatal(alg, err)
+			}
+			got, err := Open(alg, key, nonce, ciphertext, []byte("ad"))
+			if err != nil || string(got) != plaintext {
+				t.Fatalf("%s: Open = %q, %v, want %q", alg, got, err, plaintext)
+			}
+		}
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	for _, alg := range algorithms {
+		key, _ := GenerateKey(alg)
+		nonce, ciphertext, err := Seal(alg, key, []byte("110101199003071234"), []byte("ad"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range ciphertext {
+			c := append([]byte(nil), ciphertext...)
+			c[i] ^= 1
+			if _, err := Open(alg, key, nonce, c, []byte("ad")); err != ErrDecrypt {
+				t.Fatalf("%s: ciphertext byte %d flipped, err = %v", alg, i, err)
+			}
+		}
+		for i := range nonce {
+			n := append([]byte(nil), nonce...)
+			n[i] ^= 1
+			if _, err := Open(alg, key, n, ciphertext, []byte("ad")); err != ErrDecrypt {
+				t.Fatalf("%s: nonce byte %d flipped, err = %v", alg, i, err)
+			}
+		}
+		if _, err := Open(alg, key, nonce, ciphertext, []byte("ae")); err != ErrDecrypt {
+			t.Fatalf("%s: additional data changed, err = %v", alg, err)
+		}
+		if _, err := Open(alg, key, nonce, ciphertext[:len(ciphertext)-1], []byte("ad")); err != ErrDecrypt {
+			t.Fatalf("%s: truncated ciphertext, err = %v", alg, err)
+		}
+		other, _ := GenerateKey(alg)
+		if _, err := Open(alg, other, nonce, ciphertext, []byte("ad")); err != ErrDecrypt {
+			t.Fatalf("%s: wrong key, err = %v", alg, err)
+		}
+	}
+}
+
+func TestCBCTag(t *testing.T) {
+	key, _ := GenerateKey(SM4CBC)
+	aead, err := NewAEAD(SM4CBC, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	ciphertext := aead.Seal(nil, nonce, []byte("0123456789abcdef"), nil)
+	// 整分组的明文也要补一个完整的填充分组
+	if want := 2*SM4BlockSize + cbcHmacTagSize; len(ciphertext) != want {
+		t.Fatalf("len = %d, want %d", len(ciphertext), want)
+	}
+	data, tag := ciphertext[:len(ciphertext)-cbcHmacTagSize], ciphertext[len(ciphertext)-cbcHmacTagSize:]
+	// 只改标签、交换密文分组、去掉最后一个分组都必须在解密前被拒绝
+	badTag := append(append([]byte(nil), data...), tag...)
+	badTag[len(badTag)-1] ^= 0x80
+	swapped := append(append(append([]byte(nil), data[SM4BlockSize:]...), data[:SM4BlockSize]...), tag...)
+	dropped := append(append([]byte(nil), data[:SM4BlockSize]...), tag...)
+	for name, c := range map[string][]byte{"tag": badTag, "swapped": swapped, "dropped": dropped} {
+		if _, err := aead.Open(nil, nonce, c, nil); err != ErrDecrypt {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestNewAEADKeySize(t *testing.T) {
+	for _, alg := range algorithms {
+		if _, err := NewAEAD(alg, make([]byte, KeySize(alg)+1)); err == nil {
+			t.Errorf("%s accepted a wrong key size", alg)
+		}
+	}
+	if _, err := NewAEAD("A192GCM", make([]byte, 24)); err != ErrUnsupportedAlgorithm {
+		t.Errorf("err = %v", err)
+	}
+}
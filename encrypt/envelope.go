@@ -0,0 +1,306 @@
+/**
+ * @Time: 2026/10/19 18:02
+ * @Author: agent
+ */
+
+package encrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+
+	/** 密文信封版本 */
+	EnvelopeVersion = 1
+)
+
+var (
+	ErrInvalidEnvelope = errors.New("密文格式错误")
+	ErrKeyNotFound     = errors.New("加密密钥不存在")
+	ErrNoActiveKey     = errors.New("没有可用于加密的密钥")
+)
+
+// algorithmIDs 信封中算法的编号，已使用的编号不能修改
+var algorithmIDs = map[string]byte{
+	AES128GCM: 1,
+	AES256GCM: 2,
+	SM4GCM:    3,
+	SM4CBC:    4,
+}
+
+func algorithmName(id byte) string {
+	for name, v := range algorithmIDs {
+		if v == id {
+			return name
+		}
+	}
+	return ""
+}
+
+// Envelope 带版本的密文信封，二进制格式为
+// 版本(1) | 算法编号(1) | 密钥 ID 长度(1) | 密钥 ID | nonce 长度(1) | nonce | 密文
+// 密文之前的部分作为附加数据参与认证，篡改密钥 ID 或算法都会导致解密失败
+type Envelope struct {
+
+	/** 版本 */
+	Version byte
+
+	/** 加密使用的密钥 ID */
+	KeyID string
+
+	/** 算法 */
+	Algorithm string
+
+	/** 随机 nonce */
+	Nonce []byte
+
+	/** 密文，包含认证标签 */
+	Data []byte
+}
+
+// header 信封中参与认证的头部
+func (e *Envelope) header() ([]byte, error) {
+	id, ok := algorithmIDs[e.Algorithm]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(e.KeyID) > 255 || len(e.Nonce) > 255 {
+		return nil, errors.New("密钥 ID 或 nonce 过长")
+	}
+	h := make([]byte, 0, 4+len(e.KeyID)+len(e.Nonce))
+	h = append(h, e.Version, id, byte(len(e.KeyID)))
+	h = append(h, e.KeyID...)
+	h = append(h, byte(len(e.Nonce)))
+	return append(h, e.Nonce...), nil
+}
+
+// Marshal 编码为二进制
+func (e *Envelope) Marshal() ([]byte, error) {
+	h, err := e.header()
+	if err != nil {
+		return nil, err
+	}
+	return append(h, e.Data...), nil
+}
+
+// ParseEnvelope 解析二进制信封
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	if len(data) < 4 || data[0] != EnvelopeVersion {
+		return nil, ErrInvalidEnvelope
+	}
+	e := &Envelope{Version: data[0], Algorithm: algorithmName(data[1])}
+	if e.Algorithm == "" {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	rest := data[2:]
+	n := int(rest[0])
+	if len(rest) < 1+n+1 {
+		return nil, ErrInvalidEnvelope
+	}
+	e.KeyID, rest = string(rest[1:1+n]), rest[1+n:]
+	n = int(rest[0])
+	if len(rest) < 1+n {
+		return nil, ErrInvalidEnvelope
+	}
+	e.Nonce, e.Data = rest[1:1+n], rest[1+n:]
+	return e, nil
+}
+
+// Key 带 ID 的加密密钥
+type Key struct {
+
+	/** 密钥 ID，写入密文信封，解密时据此选择密钥 */
+	ID string `json:"id"`
+
+	/** 算法，AES128GCM、AES256GCM、SM4GCM 或 SM4CBC */
+	Algorithm string `json:"algorithm"`
+
+	/** 密钥，json 中为标准 base64 */
+	Secret []byte `json:"secret"`
+}
+
+// KeyRing 加密密钥环，加密使用当前密钥，解密时按信封中的密钥 ID 选择密钥
+// 轮换时加入新密钥并切换当前密钥，旧密钥需要一直保留到旧密文全部重新加密
+type KeyRing struct {
+	mu     sync.RWMutex
+	active string
+	keys   map[string]*Key
+}
+
+// NewKeyRing 创建密钥环，active 为加密使用的密钥 ID
+func NewKeyRing(active string, keys ...*Key) (*KeyRing, error) {
+	k := &KeyRing{}
+	if err := k.Replace(active, keys...); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Replace 原子地替换全部密钥
+func (k *KeyRing) Replace(active string, keys ...*Key) error {
+	m := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		if key == nil || key.ID == "" {
+			return errors.New("密钥 ID 不能为空")
+		}
+		if m[key.ID] != nil {
+			return errors.New("密钥 ID 重复：" + key.ID)
+		}
+		copied := *key
+		copied.Algorithm = strings.ToUpper(copied.Algorithm)
+		if _, ok := algorithmIDs[copied.Algorithm]; !ok {
+			return errors.New("密钥" + key.ID + "的算法不支持：" + key.Algorithm)
+		}
+		if len(copied.Secret) != KeySize(copied.Algorithm) {
+			return errors.New("密钥" + key.ID + "的长度与算法" + copied.Algorithm + "不匹配")
+		}
+		m[key.ID] = &copied
+	}
+	if m[active] == nil {
+		return errors.New("当前密钥" + active + "不在密钥环中")
+	}
+	k.mu.Lock()
+	k.active, k.keys = active, m
+	k.mu.Unlock()
+	return nil
+}
+
+// LoadKeyRing 从 json 文件加载密钥环，格式为 {"active":"k2","keys":[{"id":"k1","algorithm":"A256GCM","secret":"base64"}]}
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Active string `json:"active"`
+		Keys   []*Key `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, errors.New("密钥环文件格式错误：" + err.Error())
+	}
+	return NewKeyRing(f.Active, f.Keys...)
+}
+
+// get 按 ID 获取密钥
+func (k *KeyRing) get(id string) (*Key, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// activeKey 当前密钥，零值的密钥环没有当前密钥
+func (k *KeyRing) activeKey() (*Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key := k.keys[k.active]
+	if key == nil {
+		return nil, ErrNoActiveKey
+	}
+	return key, nil
+}
+
+// Encrypt 使用当前密钥加密，返回二进制信封
+func (k *KeyRing) Encrypt(plaintext []byte) ([]byte, error) {
+	key, err := k.activeKey()
+	if err != nil {
+		return nil, err
+	}
+	e := &Envelope{Version: EnvelopeVersion, KeyID: key.ID, Algorithm: key.Algorithm}
+	aead, err := NewAEAD(key.Algorithm, key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce = make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, e.Nonce); err != nil {
+		return nil, err
+	}
+	h, err := e.header()
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(h, e.Nonce, plaintext, h), nil
+}
+
+// Decrypt 解密二进制信封，按信封中的密钥 ID 选择密钥，可以解密旧密钥加密的数据
+func (k *KeyRing) Decrypt(data []byte) ([]byte, error) {
+	e, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.get(e.KeyID)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if key.Algorithm != e.Algorithm {
+		return nil, ErrDecrypt
+	}
+	h := data[:len(data)-len(e.Data)]
+	return Open(key.Algorithm, key.Secret, e.Nonce, e.Data, h)
+}
+
+// NeedsRotate 密文是否不是由当前密钥加密，用于逐步把旧密文重新加密
+func (k *KeyRing) NeedsRotate(data []byte) (bool, error) {
+	key, err := k.activeKey()
+	if err != nil {
+		return false, err
+	}
+	e, err := ParseEnvelope(data)
+	if err != nil {
+		return false, err
+	}
+	return e.KeyID != key.ID, nil
+}
+
+// EncryptBase64 加密并转标准 base64，适合保存到数据库
+func (k *KeyRing) EncryptBase64(plaintext string) (string, error) {
+	data, err := k.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptBase64 解密 EncryptBase64 的结果
+func (k *KeyRing) DecryptBase64(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrInvalidEnvelope
+	}
+	plaintext, err := k.Decrypt(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// EncryptHex 加密并转 hex
+func (k *KeyRing) EncryptHex(plaintext string) (string, error) {
+	data, err := k.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// DecryptHex 解密 EncryptHex 的结果
+func (k *KeyRing) DecryptHex(ciphertext string) (string, error) {
+	data, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrInvalidEnvelope
+	}
+	plaintext, err := k.Decrypt(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,140 @@
+/**
+ * @Time: 2026/10/19 18:09
+ * @Author: agent
+ */
+
+package encrypt
+
+import (
+	"testing"
+)
+
+func newTestKey(t *testing.T, id, alg string) *Key {
+	secret, err := GenerateKey(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Key{ID: id, Algorithm: alg, Secret: secret}
+}
+
+func TestKeyRingRoundTrip(t *testing.T) {
+	for _, alg := range algorithms {
+		ring, err := NewKeyRing("k1", newTestKey(t, "k1", alg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b64, err := ring.EncryptBase64("13800138000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ring.DecryptBase64(b64); err != nil || got != "13800138000" {
+			t.Fatalf("%s: DecryptBase64 = %q, %v", alg, got, err)
+		}
+		h, err := ring.EncryptHex("13800138000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := ring.DecryptHex(h); err != nil || got != "13800138000" {
+			t.Fatalf("%s: DecryptHex = %q, %v", alg, got, err)
+		}
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	k1, k2 := newTestKey(t, "k1", SM4CBC), newTestKey(t, "k2", AES256GCM)
+	old, _ := NewKeyRing("k1", k1)
+	ciphertext, err := old.Encrypt([]byte("110101199003071234"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, _ := NewKeyRing("k2", k1, k2)
+	if got, err := ring.Decrypt(ciphertext); err != nil || string(got) != "110101199003071234" {
+		t.Fatalf("Decrypt old ciphertext = %q, %v", got, err)
+	}
+	if rotate, err := ring.NeedsRotate(ciphertext); err != nil || !rotate {
+		t.Fatalf("NeedsRotate(old) = %v, %v", rotate, err)
+	}
+	fresh, _ := ring.Encrypt([]byte("110101199003071234"))
+	if rotate, err := ring.NeedsRotate(fresh); err != nil || rotate {
+		t.Fatalf("NeedsRotate(fresh) = %v, %v", rotate, err)
+	}
+	// 旧密钥移除后无法再解密
+	removed, _ := NewKeyRing("k2", k2)
+	if _, err := removed.Decrypt(ciphertext); err != ErrKeyNotFound {
+		t.Fatalf("Decrypt with removed key, err = %v", err)
+	}
+}
+
+func TestKeyRingTampered(t *testing.T) {
+	for _, alg := range algorithms {
+		key := newTestKey(t, "k1", alg)
+		ring, _ := NewKeyRing("k1", key, newTestKey(t, "k2", alg))
+		ciphertext, err := ring.Encrypt([]byte("13800138000"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range ciphertext {
+			c := append([]byte(nil), ciphertext...)
+			c[i] ^= 1
+			if _, err := ring.Decrypt(c); err == nil {
+				t.Fatalf("%s: byte %d flipped but Decrypt succeeded", alg, i)
+			}
+		}
+		// 把密钥 ID 改成另一个存在的密钥
+		e, _ := ParseEnvelope(ciphertext)
+		e.KeyID = "k2"
+		relabeled, _ := e.Marshal()
+		if _, err := ring.Decrypt(relabeled); err != ErrDecrypt {
+			t.Fatalf("%s: relabeled key ID, err = %v", alg, err)
+		}
+		// 同一个密钥 ID 换了密钥
+		wrong, _ := NewKeyRing("k1", newTestKey(t, "k1", alg))
+		if _, err := wrong.Decrypt(ciphertext); err != ErrDecrypt {
+			t.Fatalf("%s: wrong key, err = %v", alg, err)
+		}
+	}
+}
+
+func TestKeyRingAlgorithmMismatch(t *testing.T) {
+	gcm := newTestKey(t, "k1", SM4GCM)
+	ring, _ := NewKeyRing("k1", gcm)
+	ciphertext, _ := ring.Encrypt([]byte("13800138000"))
+	// 相同 ID 和密钥，但配置为另一种算法
+	cbc, _ := NewKeyRing("k1", &Key{ID: "k1", Algorithm: SM4CBC, Secret: gcm.Secret})
+	if _, err := cbc.Decrypt(ciphertext); err != ErrDecrypt {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestZeroKeyRing(t *testing.T) {
+	var ring KeyRing
+	if _, err := ring.Encrypt([]byte("x")); err != ErrNoActiveKey {
+		t.Fatalf("Encrypt err = %v", err)
+	}
+	if _, err := ring.EncryptBase64("x"); err != ErrNoActiveKey {
+		t.Fatalf("EncryptBase64 err = %v", err)
+	}
+	if _, err := ring.NeedsRotate([]byte{EnvelopeVersion, 1, 0, 0}); err != ErrNoActiveKey {
+		t.Fatalf("NeedsRotate err = %v", err)
+	}
+	if _, err := ring.Decrypt([]byte{EnvelopeVersion, 1, 1, 'k', 0}); err != ErrKeyNotFound {
+		t.Fatalf("Decrypt err = %v", err)
+	}
+}
+
+func TestParseEnvelopeMalformed(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{EnvelopeVersion},
+		{2, 1, 0, 0},
+		{EnvelopeVersion, 1, 5, 'k'},
+		{EnvelopeVersion, 1, 1, 'k', 12, 0},
+	} {
+		if _, err := ParseEnvelope(data); err != ErrInvalidEnvelope {
+			t.Errorf("ParseEnvelope(%v) err = %v", data, err)
+		}
+	}
+	if _, err := ParseEnvelope([]byte{EnvelopeVersion, 99, 0, 0}); err != ErrUnsupportedAlgorithm {
+		t.Errorf("unknown algorithm err = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+/**
+ * @Time: 2026/10/19 18:09
+ * @Author: agent
+ */
+
+package encrypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// GB/T 32907-2016 附录 A 的示例，明文与密钥相同
+const (
+	sm4VectorKey     = "0123456789abcdeffedcba9876543210"
+	sm4VectorCipher  = "681edf34d206965e86b3e94f536e4246"
+	sm4VectorMillion = "595298c7c6fd271f0402f804c33d3f66"
+)
+
+func TestSM4Vector(t *testing.T) {
+	key, _ := hex.DecodeString(sm4VectorKey)
+	block, err := NewSM4(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, SM4BlockSize)
+	block.Encrypt(out, key)
+	if got := hex.EncodeToString(out); got != sm4VectorCipher {
+		t.Fatalf("Encrypt = %s, want %s", got, sm4VectorCipher)
+	}
+	block.Decrypt(out, out)
+	if !bytes.Equal(out, key) {
+		t.Fatalf("Decrypt = %x, want %x", out, key)
+	}
+}
+
+func TestSM4VectorMillion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过一百万次迭代")
+	}
+	key, _ := hex.DecodeString(sm4VectorKey)
+	block, _ := NewSM4(key)
+	data := append([]byte(nil), key...)
+	for i := 0; i < 1000000; i++ {
+		block.Encrypt(data, data)
+	}
+	if got := hex.EncodeToString(data); got != sm4VectorMillion {
+		t.Fatalf("1000000 rounds = %s, want %s", got, sm4VectorMillion)
+	}
+}
+
+func TestSM4KeySize(t *testing.T) {
+	for _, n := range []int{0, 15, 17, 32} {
+		if _, err := NewSM4(make([]byte, n)); err == nil {
+			t.Errorf("NewSM4 accepted a %d byte key", n)
+		}
+	}
+}
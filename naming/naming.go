@@ -0,0 +1,308 @@
+/**
+ * @Time: 2026/10/19 17:42
+ * @Author: agent
+ */
+
+package naming
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+
+	/** 每种写法最多缓存的结果数，超过后整体清空重新缓存 */
+	maxCacheSize = 10000
+)
+
+const (
+	kindSnake byte = iota
+	kindScreamingSnake
+	kindKebab
+	kindCamel
+	kindPascal
+	kindCount
+)
+
+// DefaultInitialisms 默认的缩略词，转换为驼峰时保持全大写，例如 userId 转为 userID
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML",
+	"XMPP", "XSRF", "XSS",
+}
+
+var defaultConverter = NewConverter(DefaultInitialisms...)
+
+// ToSnake 转为下划线写法，例如 userID 转为 user_id，HTTPServer 转为 http_server
+func ToSnake(s string) string {
+	return defaultConverter.Snake(s)
+}
+
+// ToScreamingSnake 转为大写下划线写法，例如 userID 转为 USER_ID
+func ToScreamingSnake(s string) string {
+	return defaultConverter.ScreamingSnake(s)
+}
+
+// ToKebab 转为中划线写法，例如 userID 转为 user-id
+func ToKebab(s string) string {
+	return defaultConverter.Kebab(s)
+}
+
+// ToCamel 转为小驼峰写法，例如 user_id 转为 userID
+func ToCamel(s string) string {
+	return defaultConverter.Camel(s)
+}
+
+// ToPascal 转为大驼峰写法，例如 user_id 转为 UserID
+func ToPascal(s string) string {
+	return defaultConverter.Pascal(s)
+}
+
+// AddInitialisms 向默认转换器追加缩略词，建议在初始化时调用
+func AddInitialisms(words ...string) {
+	defaultConverter.AddInitialisms(words...)
+}
+
+// Words 将名称拆分为单词，下划线、中划线、空格、点等非字母数字字符都作为分隔符
+// 没有大小写的字母(例如汉字)后跟大写字母时也会断开，例如 名字ID 拆为 名字 ID
+func Words(s string) []string {
+	return defaultConverter.Words(s)
+}
+
+// Converter 命名转换器，可以配置各自的缩略词，转换结果会被缓存，并发安全
+// 名称开头和结尾的下划线原样保留，例如 _id 的下划线写法仍为 _id，需要使用 NewConverter 创建
+type Converter struct {
+	mu          sync.RWMutex
+	initialisms map[string]bool
+	maxLen      int
+
+	/** 每种写法的结果缓存，值为 *resultCache，命中缓存时不加锁 */
+	cache [kindCount]atomic.Value
+}
+
+// resultCache 一批转换结果，只增不改，超过容量或缩略词变化时整体替换
+type resultCache struct {
+	m    sync.Map
+	size int64
+}
+
+// NewConverter 创建命名转换器
+func NewConverter(initialisms ...string) *Converter {
+	c := &Converter{initialisms: make(map[string]bool)}
+	c.AddInitialisms(initialisms...)
+	return c
+}
+
+// AddInitialisms 追加缩略词，已缓存的结果会被清空
+func (c *Converter) AddInitialisms(words ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range words {
+		w = strings.ToUpper(strings.TrimSpace(w))
+		if w == "" {
+			continue
+		}
+		c.initialisms[w] = true
+		if n := utf8.RuneCountInString(w); n > c.maxLen {
+			c.maxLen = n
+		}
+	}
+	// 持有写锁时替换缓存，转换在读锁内取缓存和计算，旧缩略词的结果只会写入被替换掉的缓存
+	for i := range c.cache {
+		c.cache[i].Store(&resultCache{})
+	}
+}
+
+// Snake 转为下划线写法
+func (c *Converter) Snake(s string) string {
+	return c.convert(kindSnake, s)
+}
+
+// ScreamingSnake 转为大写下划线写法
+func (c *Converter) ScreamingSnake(s string) string {
+	return c.convert(kindScreamingSnake, s)
+}
+
+// Kebab 转为中划线写法
+func (c *Converter) Kebab(s string) string {
+	return c.convert(kindKebab, s)
+}
+
+// Camel 转为小驼峰写法
+func (c *Converter) Camel(s string) string {
+	return c.convert(kindCamel, s)
+}
+
+// Pascal 转为大驼峰写法
+func (c *Converter) Pascal(s string) string {
+	return c.convert(kindPascal, s)
+}
+
+// convert 先查缓存，未命中时转换并缓存，每种写法单独缓存，查询时不需要拼接 key
+func (c *Converter) convert(kind byte, s string) string {
+	if s == "" {
+		return ""
+	}
+	if out, ok := c.cache[kind].Load().(*resultCache).m.Load(s); ok {
+		return out.(string)
+	}
+	c.mu.RLock()
+	cache := c.cache[kind].Load().(*resultCache)
+	// 开头和结尾的下划线不是单词分隔符，原样保留
+	core := strings.TrimLeft(s, "_")
+	prefix := s[:len(s)-len(core)]
+	core = strings.TrimRight(core, "_")
+	suffix := s[len(prefix)+len(core):]
+	words := c.words(core)
+	var out string
+	switch kind {
+	case kindSnake:
+		out = join(words, "_", strings.ToLower)
+	case kindScreamingSnake:
+		out = join(words, "_", strings.ToUpper)
+	case kindKebab:
+		out = join(words, "-", strings.ToLower)
+	case kindCamel:
+		out = c.camel(words, false)
+	case kindPascal:
+		out = c.camel(words, true)
+	}
+	c.mu.RUnlock()
+	out = prefix + out + suffix
+	// s 可能是请求参数等大字符串的子串，复制后再缓存，避免 key 引用整个原字符串
+	if _, loaded := cache.m.LoadOrStore(string([]byte(s)), out); !loaded {
+		if atomic.AddInt64(&cache.size, 1) > maxCacheSize {
+			c.cache[kind].CompareAndSwap(cache, &resultCache{})
+		}
+	}
+	return out
+}
+
+func join(words []string, sep string, caseFn func(string) string) string {
+	var sb strings.Builder
+	for i, w := range words {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(caseFn(w))
+	}
+	return sb.String()
+}
+
+// camel 拼接驼峰，缩略词全大写，小驼峰的第一个单词全小写，调用方需持有读锁
+func (c *Converter) camel(words []string, pascal bool) string {
+	var sb strings.Builder
+	for i, w := range words {
+		if i == 0 && !pascal {
+			sb.WriteString(strings.ToLower(w))
+			continue
+		}
+		upper := strings.ToUpper(w)
+		if c.initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		// 缩略词的复数，例如 IDs
+		if len(upper) > 1 && strings.HasSuffix(upper, "S") && c.initialisms[upper[:len(upper)-1]] {
+			sb.WriteString(upper[:len(upper)-1] + "s")
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(w)
+		sb.WriteRune(unicode.ToUpper(r))
+		sb.WriteString(strings.ToLower(w[size:]))
+	}
+	return sb.String()
+}
+
+// Words 拆分单词
+// 小写或数字后跟大写时断开，连续大写在最后一个大写字母后跟小写时断开，例如 HTTPServer 拆为 HTTP Server
+// 连续大写中可以完整拆分为已知缩略词时按缩略词拆分，例如 JSONAPI 拆为 JSON API
+// 已知缩略词后跟 s 视为复数，例如 userIDs 拆为 user IDs
+func (c *Converter) Words(s string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.words(s)
+}
+
+// words 拆分单词，调用方需持有读锁
+func (c *Converter) words(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, c.splitInitialisms(string(runes[start:end]))...)
+		}
+		start = -1
+	}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && !unicode.IsUpper(prev):
+			// 小写、数字或没有大小写的字母后跟大写
+			flush(i)
+			start = i
+		case unicode.IsLower(r) && unicode.IsUpper(prev) && i-1 > start:
+			// 连续大写后出现小写：HTTPServer 在 S 前断开，但 IDs 这种缩略词复数保持完整
+			if r == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1])) && c.isInitialism(string(runes[start:i])) {
+				continue
+			}
+			flush(i - 1)
+			start = i - 1
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+func (c *Converter) isInitialism(w string) bool {
+	return c.initialisms[strings.ToUpper(w)]
+}
+
+// splitInitialisms 全大写的单词能完整拆分为多个已知缩略词时拆开，否则原样返回，调用方需持有读锁
+func (c *Converter) splitInitialisms(w string) []string {
+	runes := []rune(w)
+	if len(runes) < 2 {
+		return []string{w}
+	}
+	for _, r := range runes {
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) {
+			return []string{w}
+		}
+	}
+	if c.initialisms[w] {
+		return []string{w}
+	}
+	var parts []string
+	for i := 0; i < len(runes); {
+		n := c.maxLen
+		if n > len(runes)-i {
+			n = len(runes) - i
+		}
+		for ; n > 0; n-- {
+			if c.initialisms[string(runes[i:i+n])] {
+				break
+			}
+		}
+		if n == 0 {
+			return []string{w}
+		}
+		parts = append(parts, string(runes[i:i+n]))
+		i += n
+	}
+	return parts
+}
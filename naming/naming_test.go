@@ -0,0 +1,182 @@
+/**
+ * @Time: 2026/10/19 19:10
+ * @Author: agent
+ */
+
+package naming
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"unicode"
+)
+
+// baselineCamelToCase 基线版本的 page.CamelToCase，每个大写字母前加下划线
+func baselineCamelToCase(name string) string {
+	buffer := new(bytes.Buffer)
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i != 0 {
+				buffer.WriteRune('_')
+			}
+			buffer.WriteRune(unicode.ToLower(r))
+		} else {
+			buffer.WriteRune(r)
+		}
+	}
+	return buffer.String()
+}
+
+func TestToSnakeMatchesBaseline(t *testing.T) {
+	// 不含缩略词的写法与基线版本结果一致
+	for _, s := range []string{
+		"userName", "user_name", "_id", "id", "createTime", "name2", "Name", "name_", "__v",
+		"_userName_", "deptIdList", "a", "userName2Id", "名字", "用户Name",
+	} {
+		if got, want := ToSnake(s), baselineCamelToCase(s); got != want {
+			t.Errorf("ToSnake(%q) = %q, baseline %q", s, got, want)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		in, snake, screaming, kebab, camel, pascal string
+	}{
+		{"userName", "user_name", "USER_NAME", "user-name", "userName", "UserName"},
+		{"user_id", "user_id", "USER_ID", "user-id", "userID", "UserID"},
+		{"userID", "user_id", "USER_ID", "user-id", "userID", "UserID"},
+		{"userIDs", "user_ids", "USER_IDS", "user-ids", "userIDs", "UserIDs"},
+		{"HTTPServer", "http_server", "HTTP_SERVER", "http-server", "httpServer", "HTTPServer"},
+		{"JSONAPI", "json_api", "JSON_API", "json-api", "jsonAPI", "JSONAPI"},
+		{"XMLHttpRequest", "xml_http_request", "XML_HTTP_REQUEST", "xml-http-request", "xmlHTTPRequest", "XMLHTTPRequest"},
+		{"x-request-id", "x_request_id", "X_REQUEST_ID", "x-request-id", "xRequestID", "XRequestID"},
+		{"userName2Id", "user_name2_id", "USER_NAME2_ID", "user-name2-id", "userName2ID", "UserName2ID"},
+		// 开头和结尾的下划线原样保留
+		{"_id", "_id", "_ID", "_id", "_id", "_ID"},
+		{"_userName_", "_user_name_", "_USER_NAME_", "_user-name_", "_userName_", "_UserName_"},
+		// 没有大小写的字母后跟大写时断开
+		{"名字ID", "名字_id", "名字_ID", "名字-id", "名字ID", "名字ID"},
+		{"用户Name", "用户_name", "用户_NAME", "用户-name", "用户Name", "用户Name"},
+		{"ÉtéID", "été_id", "ÉTÉ_ID", "été-id", "étéID", "ÉtéID"},
+		{"", "", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ToSnake(tt.in); got != tt.snake {
+			t.Errorf("ToSnake(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := ToScreamingSnake(tt.in); got != tt.screaming {
+			t.Errorf("ToScreamingSnake(%q) = %q, want %q", tt.in, got, tt.screaming)
+		}
+		if got := ToKebab(tt.in); got != tt.kebab {
+			t.Errorf("ToKebab(%q) = %q, want %q", tt.in, got, tt.kebab)
+		}
+		if got := ToCamel(tt.in); got != tt.camel {
+			t.Errorf("ToCamel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := ToPascal(tt.in); got != tt.pascal {
+			t.Errorf("ToPascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		// 下划线写法再转回大驼峰结果不变
+		if got := ToPascal(tt.snake); got != tt.pascal {
+			t.Errorf("ToPascal(ToSnake(%q)) = %q, want %q", tt.in, got, tt.pascal)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"userIDs":       {"user", "IDs"},
+		"HTTPServer":    {"HTTP", "Server"},
+		"JSONAPI":       {"JSON", "API"},
+		"ABCDef":        {"ABC", "Def"},
+		"a.b c-d_e":     {"a", "b", "c", "d", "e"},
+		"名字ID":          {"名字", "ID"},
+		"名字id":          {"名字id"},
+		"version2Alpha": {"version2", "Alpha"},
+	}
+	for in, want := range tests {
+		if got := Words(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("Words(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestConverterInitialisms(t *testing.T) {
+	c := NewConverter("ID")
+	if got := c.Camel("user_sku"); got != "userSku" {
+		t.Fatalf("Camel = %q", got)
+	}
+	// 追加缩略词后旧的缓存结果失效
+	c.AddInitialisms(" sku ")
+	if got := c.Camel("user_sku"); got != "userSKU" {
+		t.Fatalf("Camel after AddInitialisms = %q", got)
+	}
+	if got := c.Snake("userSKUID"); got != "user_sku_id" {
+		t.Fatalf("Snake = %q", got)
+	}
+	if got := NewConverter().Pascal("user_id"); got != "UserId" {
+		t.Fatalf("Pascal without initialisms = %q", got)
+	}
+}
+
+func TestConverterConcurrent(t *testing.T) {
+	c := NewConverter("ID")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				if got := c.Snake("userID" + strconv.Itoa(i%50)); got != "user_id"+strconv.Itoa(i%50) {
+					t.Errorf("Snake = %q", got)
+					return
+				}
+				if g == 0 && i%500 == 0 {
+					c.AddInitialisms("SKU")
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	// 并发追加缩略词后，缓存中不能留下按旧缩略词转换的结果
+	c = NewConverter()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			c.Camel("user_sku")
+		}
+		close(done)
+	}()
+	c.AddInitialisms("SKU")
+	<-done
+	if got := c.Camel("user_sku"); got != "userSKU" {
+		t.Fatalf("stale cached result %q", got)
+	}
+}
+
+func TestConverterCacheBound(t *testing.T) {
+	c := NewConverter()
+	for i := 0; i < maxCacheSize*2+10; i++ {
+		c.Snake("name" + strconv.Itoa(i))
+	}
+	cache := c.cache[kindSnake].Load().(*resultCache)
+	if cache.size > maxCacheSize {
+		t.Fatalf("cache size = %d", cache.size)
+	}
+	if got := c.Snake("userName"); got != "user_name" {
+		t.Fatalf("Snake after reset = %q", got)
+	}
+}
+
+func BenchmarkToSnakeCached(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ToSnake("createTime")
+		}
+	})
+}
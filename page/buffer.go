@@ -0,0 +1,269 @@
+/**
+ * @Time: 2026/10/19 17:45
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+
+	/** 默认的时间格式，与 convert.AsString 一致 */
+	DefaultTimeLayout = "2006-01-02 15:04:05"
+
+	/** 超过该容量的缓冲区不放回池中，避免偶尔的大对象长期占用内存 */
+	maxPooledBufferSize = 64 << 10
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return NewBuffer()
+	},
+}
+
+// Buffer 内嵌bytes.Buffer，支持连写
+// 写入失败（例如内存不足）时记录错误，之后的写入都会被忽略，写完后通过 Err 检查
+type Buffer struct {
+	*bytes.Buffer
+
+	/** 第一次写入失败的错误 */
+	err error
+
+	/** 严格模式，Append 遇到不支持的类型时记录错误，否则按 %v 格式写入 */
+	strict bool
+
+	/** 浮点数格式，参考 strconv.FormatFloat */
+	floatFmt byte
+
+	/** 浮点数精度，-1 表示最短表示 */
+	floatPrec int
+
+	/** 时间格式 */
+	timeLayout string
+
+	/** 数字格式化使用的临时空间，避免分配 */
+	scratch [64]byte
+}
+
+// NewBuffer 创建缓冲区
+func NewBuffer() *Buffer {
+	b := &Buffer{Buffer: new(bytes.Buffer)}
+	b.defaults()
+	return b
+}
+
+// AcquireBuffer 从池中获取缓冲区，用完后调用 ReleaseBuffer 放回，放回后不能再使用
+func AcquireBuffer() *Buffer {
+	return bufferPool.Get().(*Buffer)
+}
+
+// ReleaseBuffer 清空缓冲区和格式设置后放回池中
+func ReleaseBuffer(b *Buffer) {
+	if b == nil || b.Buffer == nil || b.Cap() > maxPooledBufferSize {
+		return
+	}
+	b.Reset()
+	b.defaults()
+	bufferPool.Put(b)
+}
+
+func (b *Buffer) defaults() {
+	b.strict = false
+	b.floatFmt = 'f'
+	b.floatPrec = -1
+	b.timeLayout = DefaultTimeLayout
+}
+
+// Strict 设置严格模式
+func (b *Buffer) Strict(strict bool) *Buffer {
+	b.strict = strict
+	return b
+}
+
+// FloatFormat 设置浮点数格式，format 和 prec 的含义同 strconv.FormatFloat
+func (b *Buffer) FloatFormat(format byte, prec int) *Buffer {
+	b.floatFmt = format
+	b.floatPrec = prec
+	return b
+}
+
+// TimeLayout 设置时间格式
+func (b *Buffer) TimeLayout(layout string) *Buffer {
+	b.timeLayout = layout
+	return b
+}
+
+// Err 第一次写入失败的错误
+func (b *Buffer) Err() error {
+	return b.err
+}
+
+// Reset 清空内容和错误，保留格式设置
+func (b *Buffer) Reset() {
+	b.Buffer.Reset()
+	b.err = nil
+}
+
+// Append 按类型写入任意值
+// rune 与 int32 是同一类型，按字符写入，与原来的行为一致，需要写入 int32 数字时使用 AppendInt
+// byte 与 uint8 是同一类型，按数字写入，需要写入字节时使用 AppendByte
+// 指针会先取值，nil 写入空串
+func (b *Buffer) Append(i interface{}) *Buffer {
+	if b.err != nil {
+		return b
+	}
+	switch val := i.(type) {
+	case nil:
+	case string:
+		b.AppendString(val)
+	case []byte:
+		b.AppendBytes(val)
+	case int:
+		b.AppendInt(int64(val))
+	case int8:
+		b.AppendInt(int64(val))
+	case int16:
+		b.AppendInt(int64(val))
+	case rune:
+		b.AppendRune(val)
+	case int64:
+		b.AppendInt(val)
+	case uint:
+		b.AppendUint(uint64(val))
+	case uint8:
+		b.AppendUint(uint64(val))
+	case uint16:
+		b.AppendUint(uint64(val))
+	case uint32:
+		b.AppendUint(uint64(val))
+	case uint64:
+		b.AppendUint(val)
+	case float32:
+		b.appendFloat(float64(val), 32)
+	case float64:
+		b.appendFloat(val, 64)
+	case bool:
+		b.AppendBool(val)
+	case time.Time:
+		b.AppendTime(val)
+	case error:
+		b.AppendString(val.Error())
+	case fmt.Stringer:
+		b.AppendString(val.String())
+	default:
+		b.appendOther(i)
+	}
+	return b
+}
+
+// appendOther 指针取值后再写入，其余类型在严格模式下报错，否则按 %v 写入
+func (b *Buffer) appendOther(i interface{}) {
+	v := reflect.ValueOf(i)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		b.Append(v.Elem().Interface())
+		return
+	}
+	if b.strict {
+		b.err = fmt.Errorf("Buffer 不支持的类型：%T", i)
+		return
+	}
+	defer b.recoverTooLarge()
+	_, _ = fmt.Fprint(b.Buffer, i)
+}
+
+// AppendString 写入字符串
+func (b *Buffer) AppendString(s string) *Buffer {
+	if b.err != nil {
+		return b
+	}
+	defer b.recoverTooLarge()
+	_, _ = b.WriteString(s)
+	return b
+}
+
+// AppendBytes 写入字节
+func (b *Buffer) AppendBytes(p []byte) *Buffer {
+	if b.err != nil {
+		return b
+	}
+	defer b.recoverTooLarge()
+	_, _ = b.Write(p)
+	return b
+}
+
+// AppendByte 写入单个字节
+func (b *Buffer) AppendByte(c byte) *Buffer {
+	if b.err != nil {
+		return b
+	}
+	defer b.recoverTooLarge()
+	_ = b.WriteByte(c)
+	return b
+}
+
+// AppendRune 写入字符
+func (b *Buffer) AppendRune(r rune) *Buffer {
+	if b.err != nil {
+		return b
+	}
+	defer b.recoverTooLarge()
+	_, _ = b.WriteRune(r)
+	return b
+}
+
+// AppendInt 写入十进制整数
+func (b *Buffer) AppendInt(n int64) *Buffer {
+	return b.AppendBytes(strconv.AppendInt(b.scratch[:0], n, 10))
+}
+
+// AppendUint 写入十进制无符号整数
+func (b *Buffer) AppendUint(n uint64) *Buffer {
+	return b.AppendBytes(strconv.AppendUint(b.scratch[:0], n, 10))
+}
+
+// AppendFloat 按设置的格式写入浮点数
+func (b *Buffer) AppendFloat(f float64) *Buffer {
+	return b.appendFloat(f, 64)
+}
+
+func (b *Buffer) appendFloat(f float64, bitSize int) *Buffer {
+	return b.AppendBytes(strconv.AppendFloat(b.scratch[:0], f, b.floatFmt, b.floatPrec, bitSize))
+}
+
+// AppendBool 写入 true 或 false
+func (b *Buffer) AppendBool(v bool) *Buffer {
+	return b.AppendBytes(strconv.AppendBool(b.scratch[:0], v))
+}
+
+// AppendTime 按设置的格式写入时间
+func (b *Buffer) AppendTime(t time.Time) *Buffer {
+	return b.AppendBytes(t.AppendFormat(b.scratch[:0], b.timeLayout))
+}
+
+// AppendQuote 写入带双引号和转义的字符串
+func (b *Buffer) AppendQuote(s string) *Buffer {
+	return b.AppendBytes(strconv.AppendQuote(b.scratch[:0], s))
+}
+
+// recoverTooLarge bytes.Buffer 扩容失败时会 panic，这里转为错误记录下来，需要在写入前 defer 调用
+func (b *Buffer) recoverTooLarge() {
+	if r := recover(); r != nil {
+		if err, ok := r.(error); ok && errors.Is(err, bytes.ErrTooLarge) {
+			b.err = bytes.ErrTooLarge
+			return
+		}
+		panic(r)
+	}
+}
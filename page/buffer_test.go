@@ -0,0 +1,113 @@
+/**
+ * @Time: 2026/10/19 17:45
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type bufferStringer struct{}
+
+func (bufferStringer) String() string { return "stringer" }
+
+func TestBufferAppend(t *testing.T) {
+	n := 7
+	var nilPtr *int
+	at := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{'_', "_"},
+		{'名', "名"},
+		{"abc", "abc"},
+		{[]byte("xy"), "xy"},
+		{-3, "-3"},
+		{int8(-8), "-8"},
+		{int16(16), "16"},
+		{int64(-64), "-64"},
+		{uint(1), "1"},
+		{uint8(65), "65"},
+		{uint16(16), "16"},
+		{uint32(32), "32"},
+		{uint64(64), "64"},
+		{float32(1.5), "1.5"},
+		{0.25, "0.25"},
+		{true, "true"},
+		{at, "2026-10-19 08:30:00"},
+		{errors.New("失败"), "失败"},
+		{bufferStringer{}, "stringer"},
+		{&n, "7"},
+		{nilPtr, ""},
+		{nil, ""},
+		{struct{ A int }{1}, "{1}"},
+	}
+	for _, tt := range tests {
+		b := NewBuffer()
+		if got := b.Append(tt.value).String(); got != tt.want {
+			t.Errorf("Append(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBufferAppendNumbers(t *testing.T) {
+	b := NewBuffer()
+	b.AppendInt(int64(int32(-5))).AppendByte(',').AppendUint(9).AppendByte(',').AppendQuote(`a"b`)
+	if got := b.String(); got != `-5,9,"a\"b"` {
+		t.Fatalf("got %q", got)
+	}
+	b.Reset()
+	b.FloatFormat('f', 2).AppendFloat(1.0 / 3)
+	if got := b.String(); got != "0.33" {
+		t.Fatalf("float = %q", got)
+	}
+	b.Reset()
+	b.TimeLayout("2006/01/02").AppendTime(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	if got := b.String(); got != "2026/10/19" {
+		t.Fatalf("time = %q", got)
+	}
+}
+
+func TestBufferStrict(t *testing.T) {
+	b := NewBuffer().Strict(true)
+	b.Append("a").Append(struct{}{}).Append("b")
+	if b.Err() == nil {
+		t.Fatal("strict mode accepted an unsupported type")
+	}
+	if got := b.String(); got != "a" {
+		t.Fatalf("writes after the error = %q", got)
+	}
+	b.Reset()
+	if b.Err() != nil || b.Append("c").String() != "c" {
+		t.Fatalf("Reset did not clear the error: %v", b.Err())
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	b := AcquireBuffer()
+	b.Strict(true).FloatFormat('e', 1).AppendString("used")
+	ReleaseBuffer(b)
+	b = AcquireBuffer()
+	defer ReleaseBuffer(b)
+	if b.Len() != 0 || b.strict || b.floatFmt != 'f' || b.floatPrec != -1 || b.timeLayout != DefaultTimeLayout {
+		t.Fatalf("pooled buffer not reset: %+v", b)
+	}
+	ReleaseBuffer(nil)
+}
+
+func TestBufferAppendAllocs(t *testing.T) {
+	b := NewBuffer()
+	b.Grow(1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Reset()
+		b.AppendString("id").AppendRune('_').AppendByte('=').AppendInt(42).AppendFloat(1.5).AppendBool(true)
+	})
+	if allocs != 0 {
+		t.Fatalf("Append* allocates %v times per run", allocs)
+	}
+}
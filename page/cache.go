@@ -0,0 +1,296 @@
+/**
+ * @Time: 2026/10/19 17:41
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheKey 分页参数的规范化哈希，条件的书写顺序不影响结果，可以作为缓存 key
+// 包含当前页、行数、表名、排序、全部条件以及范围条件，不同租户的相同查询得到不同的 key
+func (p *PageInfo) CacheKey() string {
+	var sb strings.Builder
+	sb.WriteString("v1|current=" + strconv.Itoa(p.Current))
+	sb.WriteString("|rowCount=" + strconv.Itoa(p.RowCount))
+	sb.WriteString("|skip=" + strconv.Itoa(p.Skip))
+	sb.WriteString("|table=" + strconv.Quote(p.TableName))
+	sb.WriteString("|order=" + strconv.Quote(p.OrderStr))
+	writeCanonicalParams(&sb, "and", p.AndParams)
+	writeCanonicalParams(&sb, "or", p.OrParams)
+	sb.WriteString("|filter=" + canonicalCond(p.Filter))
+	sb.WriteString("|orFilter=" + canonicalCond(p.OrFilter))
+	sb.WriteString("|scope=" + canonicalCond(p.Scope))
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeCanonicalParams(sb *strings.Builder, name string, params map[string]interface{}) {
+	sb.WriteString("|" + name + "=")
+	for _, key := range sortedKeys(params) {
+		sb.WriteString(strconv.Quote(key) + ":" + canonicalValue(params[key]) + ";")
+	}
+}
+
+// canonicalCond 条件树的规范化表示，同一分组内的子条件按规范化结果排序
+func canonicalCond(c *Cond) string {
+	if c == nil {
+		return "nil"
+	}
+	prefix := ""
+	if c.Not {
+		prefix = "!"
+	}
+	if c.IsLeaf() {
+		return prefix + strconv.Quote(c.Key()) + ":" + canonicalValue(c.Value)
+	}
+	children := make([]string, len(c.Children))
+	for i, child := range c.Children {
+		children[i] = canonicalCond(child)
+	}
+	sort.Strings(children)
+	return prefix + c.Logic + "(" + strings.Join(children, ",") + ")"
+}
+
+// canonicalValue 带类型的值表示，字符串 "1" 和数字 1 不会相同
+func canonicalValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+	return fmt.Sprintf("%T:%s", v, b)
+}
+
+// CacheStore 分页结果的缓存后端
+type CacheStore interface {
+
+	// Get 读取缓存，不存在或已过期返回 false
+	Get(key string) (interface{}, bool)
+
+	// Set 写入缓存，table 用于按表失效
+	Set(key string, value interface{}, ttl time.Duration, table string)
+
+	// InvalidateTable 删除该表的全部缓存
+	InvalidateTable(table string)
+}
+
+// DefaultLoadTimeout 合并加载的默认超时时间
+const DefaultLoadTimeout = 30 * time.Second
+
+// PageCache 分页查询的旁路缓存
+// 相同查询并发未命中时只执行一次加载，其余请求等待并共享结果
+// 缓存的值会被多个请求共享，调用方不能修改
+type PageCache struct {
+	store       CacheStore
+	ttl         time.Duration
+	loadTimeout time.Duration
+
+	mu          sync.Mutex
+	generations map[string]uint64
+	calls       map[string]*cacheCall
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewPageCache 创建分页缓存，ttl 为缓存有效期
+func NewPageCache(store CacheStore, ttl time.Duration) *PageCache {
+	return &PageCache{
+		store:       store,
+		ttl:         ttl,
+		loadTimeout: DefaultLoadTimeout,
+		generations: make(map[string]uint64),
+		calls:       make(map[string]*cacheCall),
+	}
+}
+
+// SetLoadTimeout 设置合并加载的超时时间，不大于 0 时不限制
+func (pc *PageCache) SetLoadTimeout(timeout time.Duration) {
+	pc.mu.Lock()
+	pc.loadTimeout = timeout
+	pc.mu.Unlock()
+}
+
+// Get 先读缓存，未命中时调用 load 并写入缓存，load 出错时不缓存
+// load 在独立的 goroutine 中执行，使用的 ctx 保留调用方的值但不随任何一个调用方取消，
+// 调用方取消时只是自己不再等待，其余等待同一结果的请求不受影响
+func (pc *PageCache) Get(ctx context.Context, table string, p *PageInfo, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	key := table + ":" + p.CacheKey()
+	if value, ok := pc.store.Get(key); ok {
+		return value, nil
+	}
+	pc.mu.Lock()
+	call, ok := pc.calls[key]
+	if !ok {
+		call = &cacheCall{done: make(chan struct{})}
+		pc.calls[key] = call
+		go pc.load(detachedContext{ctx}, key, table, pc.generations[table], pc.loadTimeout, call, load)
+	}
+	pc.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load 执行加载，load 发生 panic 时转为错误返回给全部等待方，不会让该 key 一直处于加载中
+func (pc *PageCache) load(ctx context.Context, key, table string, generation uint64, timeout time.Duration, call *cacheCall, load func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.value, call.err = nil, fmt.Errorf("加载分页数据异常：%v", r)
+			log.Println(call.err.Error())
+		}
+		pc.mu.Lock()
+		delete(pc.calls, key)
+		// 加载期间表被失效过，结果可能是旧数据，不写入缓存
+		if call.err == nil && pc.generations[table] == generation {
+			pc.store.Set(key, call.value, pc.ttl, table)
+		}
+		pc.mu.Unlock()
+		close(call.done)
+	}()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	call.value, call.err = load(ctx)
+}
+
+// detachedContext 保留父 ctx 的值，但没有截止时间也不会被取消
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Invalidate 表数据变更后调用，删除该表的全部分页缓存
+func (pc *PageCache) Invalidate(table string) {
+	pc.mu.Lock()
+	pc.generations[table]++
+	pc.mu.Unlock()
+	pc.store.InvalidateTable(table)
+}
+
+// LRUCache 带过期时间和容量上限的内存 LRU 缓存
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	tables   map[string]map[string]struct{}
+	order    *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	table   string
+	expires time.Time
+}
+
+// NewLRUCache 创建内存 LRU 缓存，capacity 为最多缓存的条数
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		tables:   make(map[string]map[string]struct{}),
+		order:    list.New(),
+	}
+}
+
+func (l *LRUCache) Get(key string) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		l.remove(elem)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *LRUCache) Set(key string, value interface{}, ttl time.Duration, table string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if elem, ok := l.items[key]; ok {
+		l.remove(elem)
+	}
+	entry := &lruEntry{key: key, value: value, table: table}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	l.items[key] = l.order.PushFront(entry)
+	if l.tables[table] == nil {
+		l.tables[table] = make(map[string]struct{})
+	}
+	l.tables[table][key] = struct{}{}
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRUCache) InvalidateTable(table string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key := range l.tables[table] {
+		if elem, ok := l.items[key]; ok {
+			l.remove(elem)
+		}
+	}
+	delete(l.tables, table)
+}
+
+// Len 当前缓存的条数，包含已过期但还未被清理的条目
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRUCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	l.order.Remove(elem)
+	delete(l.items, entry.key)
+	if keys := l.tables[entry.table]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(l.tables, entry.table)
+		}
+	}
+}
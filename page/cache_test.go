@@ -0,0 +1,209 @@
+/**
+ * @Time: 2026/10/19 19:00
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	a, _ := ParsePageParam("status=1&age=gte:18&current=2")
+	b, _ := ParsePageParam("current=2&age=gte:18&status=1")
+	if a.CacheKey() != b.CacheKey() {
+		t.Error("parameter order changed the key")
+	}
+	a.Filter = Or(Compare("vip", "=", 1), Compare("level", ">", 3))
+	b.Filter = Or(Compare("level", ">", 3), Compare("vip", "=", 1))
+	if a.CacheKey() != b.CacheKey() {
+		t.Error("condition order changed the key")
+	}
+	for name, change := range map[string]func(p *PageInfo){
+		"current": func(p *PageInfo) { p.Current = 3 },
+		"skip":    func(p *PageInfo) { p.Skip = 5 },
+		"scope":   func(p *PageInfo) { p.AddScope(Compare("tenant_id", "=", 1)) },
+		"type":    func(p *PageInfo) { p.AndParams["status = ?"] = 1 },
+		"not":     func(p *PageInfo) { p.Filter = Not(p.Filter) },
+	} {
+		c := b.Clone()
+		change(c)
+		if c.CacheKey() == b.CacheKey() {
+			t.Errorf("%s did not change the key", name)
+		}
+	}
+}
+
+func TestPageCacheSingleflight(t *testing.T) {
+	pc := NewPageCache(NewLRUCache(10), time.Minute)
+	info, _ := ParsePageParam("status=1")
+	var calls int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "rows", nil
+	}
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = pc.Get(context.Background(), "user", info, load)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("load called %d times", calls)
+	}
+	for i, r := range results {
+		if r != "rows" {
+			t.Errorf("result %d = %v", i, r)
+		}
+	}
+	// 之后直接命中缓存
+	if v, err := pc.Get(context.Background(), "user", info, load); v != "rows" || err != nil || calls != 1 {
+		t.Errorf("cached Get = %v %v, calls %d", v, err, calls)
+	}
+}
+
+func TestPageCacheInvalidateDuringLoad(t *testing.T) {
+	pc := NewPageCache(NewLRUCache(10), time.Minute)
+	info, _ := ParsePageParam("status=1")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan interface{})
+	go func() {
+		v, _ := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return "old", nil
+		})
+		done <- v
+	}()
+	<-started
+	pc.Invalidate("user")
+	close(release)
+	if v := <-done; v != "old" {
+		t.Fatalf("Get = %v", v)
+	}
+	// 加载期间表被失效，旧结果不写入缓存
+	v, _ := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+		return "new", nil
+	})
+	if v != "new" {
+		t.Fatalf("stale result cached: %v", v)
+	}
+}
+
+func TestPageCacheErrorAndPanic(t *testing.T) {
+	pc := NewPageCache(NewLRUCache(10), time.Minute)
+	info, _ := ParsePageParam("status=1")
+	if _, err := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("db down")
+	}); err == nil || err.Error() != "db down" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	}); err == nil {
+		t.Fatal("panic not reported")
+	}
+	// 出错和 panic 都不缓存，也不会让该 key 一直处于加载中
+	v, err := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+		return "rows", nil
+	})
+	if v != "rows" || err != nil {
+		t.Fatalf("Get after panic = %v %v", v, err)
+	}
+}
+
+func TestPageCacheCallerCancel(t *testing.T) {
+	pc := NewPageCache(NewLRUCache(10), time.Minute)
+	info, _ := ParsePageParam("status=1")
+	release := make(chan struct{})
+	var loadErr error
+	load := func(ctx context.Context) (interface{}, error) {
+		<-release
+		loadErr = ctx.Err()
+		return "rows", nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := pc.Get(ctx, "user", info, load)
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	second := make(chan interface{})
+	go func() {
+		v, _ := pc.Get(context.Background(), "user", info, load)
+		second <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// 第一个调用方取消只影响自己，加载继续，其余等待方拿到结果
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller err = %v", err)
+	}
+	close(release)
+	if v := <-second; v != "rows" {
+		t.Fatalf("waiter got %v", v)
+	}
+	if loadErr != nil {
+		t.Fatalf("load ctx err = %v", loadErr)
+	}
+}
+
+func TestPageCacheLoadTimeout(t *testing.T) {
+	pc := NewPageCache(NewLRUCache(10), time.Minute)
+	pc.SetLoadTimeout(10 * time.Millisecond)
+	info, _ := ParsePageParam("status=1")
+	_, err := pc.Get(context.Background(), "user", info, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	l := NewLRUCache(2)
+	l.Set("a", 1, 0, "t1")
+	l.Set("b", 2, 0, "t2")
+	l.Get("a")
+	l.Set("c", 3, 0, "t1")
+	// b 最久未使用，被淘汰
+	if _, ok := l.Get("b"); ok {
+		t.Error("b not evicted")
+	}
+	if v, ok := l.Get("a"); !ok || v != 1 {
+		t.Errorf("a = %v %v", v, ok)
+	}
+	l.InvalidateTable("t1")
+	if l.Len() != 0 {
+		t.Errorf("Len after InvalidateTable = %d", l.Len())
+	}
+	l.Set("d", 4, time.Millisecond, "t1")
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := l.Get("d"); ok {
+		t.Error("expired entry returned")
+	}
+	for i := 0; i < 10; i++ {
+		l.Set(strconv.Itoa(i), i, 0, "t")
+	}
+	if l.Len() != 2 {
+		t.Errorf("Len = %d, want 2", l.Len())
+	}
+}
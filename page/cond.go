@@ -0,0 +1,299 @@
+/**
+ * @Time: 2026/10/19 17:36
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+
+	/** ------- 条件连接词 ------  */
+	logicAnd = "AND"
+	logicOr  = "OR"
+)
+
+// Cond 查询条件树
+// 叶子节点是一个比较条件，Key() 与 AndParams 的 key 格式相同，例如 "age >= ?"
+// 分组节点用 AND 或 OR 连接子条件，用于表达 map 无法表达的分组和同一列上的多个条件
+type Cond struct {
+
+	/** 分组连接词 AND 或 OR，叶子节点为空 */
+	Logic string `json:"logic,omitempty"`
+
+	/** 分组的子条件 */
+	Children []*Cond `json:"children,omitempty"`
+
+	/** 是否取反 */
+	Not bool `json:"not,omitempty"`
+
+	/** 列名，下划线写法 */
+	Column string `json:"column,omitempty"`
+
+	/** SQL 比较符，例如 = <> < LIKE IN IS NULL */
+	Op string `json:"op,omitempty"`
+
+	/** 比较值，IN 和 NOT IN 为切片，IS NULL 为 nil */
+	Value interface{} `json:"value,omitempty"`
+
+	/** 原样输出的 SQL 片段，最多含一个 ? 占位符，对应 Value，设置后忽略 Column 和 Op */
+	Raw string `json:"raw,omitempty"`
+}
+
+// Compare 比较条件
+func Compare(column, op string, value interface{}) *Cond {
+	return &Cond{Column: column, Op: op, Value: value}
+}
+
+// And 用 AND 连接多个条件，nil 会被忽略
+func And(conds ...*Cond) *Cond {
+	return group(logicAnd, conds)
+}
+
+// Or 用 OR 连接多个条件，nil 会被忽略
+func Or(conds ...*Cond) *Cond {
+	return group(logicOr, conds)
+}
+
+// Not 条件取反
+func Not(c *Cond) *Cond {
+	if c == nil {
+		return nil
+	}
+	n := *c
+	n.Not = !c.Not
+	return &n
+}
+
+func group(logic string, conds []*Cond) *Cond {
+	children := make([]*Cond, 0, len(conds))
+	for _, c := range conds {
+		if c == nil {
+			continue
+		}
+		// 同类分组直接展开，避免多余的括号
+		if c.Logic == logic && !c.Not {
+			children = append(children, c.Children...)
+			continue
+		}
+		children = append(children, c)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &Cond{Logic: logic, Children: children}
+}
+
+// Clone 复制整棵条件树，修改副本的节点不会影响原条件，Value 中的切片仍然共享
+func (c *Cond) Clone() *Cond {
+	if c == nil {
+		return nil
+	}
+	n := *c
+	if c.Children != nil {
+		n.Children = make([]*Cond, len(c.Children))
+		for i, child := range c.Children {
+			n.Children[i] = child.Clone()
+		}
+	}
+	return &n
+}
+
+// IsLeaf 是否为比较条件
+func (c *Cond) IsLeaf() bool {
+	return c.Logic == ""
+}
+
+// Key 叶子条件的 map key 形式，与 AndParams 一致
+func (c *Cond) Key() string {
+	if c.Raw != "" {
+		return c.Raw
+	}
+	if c.Op == "IS NULL" || c.Op == "IS NOT NULL" {
+		return c.Column + " " + c.Op
+	}
+	return c.Column + " " + c.Op + " ?"
+}
+
+// SQL 按默认方言渲染为带 ? 占位符的 SQL 片段和参数，IN 的切片参数会展开成多个占位符
+func (c *Cond) SQL() (string, []interface{}) {
+	return c.SQLDialect(DefaultDialect)
+}
+
+// SQLDialect 按指定方言渲染，方言只影响自定义操作符
+func (c *Cond) SQLDialect(d Dialect) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	c.render(&sb, &args, d)
+	return sb.String(), args
+}
+
+func (c *Cond) render(sb *strings.Builder, args *[]interface{}, d Dialect) {
+	if c.Not {
+		sb.WriteString("NOT (")
+		defer sb.WriteString(")")
+	}
+	if c.IsLeaf() {
+		if c.Raw == "" && !isSQLOp(c.Op) {
+			if sql, values, ok := renderCustom(c, d); ok {
+				sb.WriteString(sql)
+				*args = append(*args, values...)
+				return
+			}
+		}
+		renderKey(sb, args, c.Key(), c.Value)
+		return
+	}
+	for i, child := range c.Children {
+		if i > 0 {
+			sb.WriteString(" " + c.Logic + " ")
+		}
+		writeGroup(sb, args, child, d)
+	}
+}
+
+// writeGroup 渲染子条件，分组加括号
+func writeGroup(sb *strings.Builder, args *[]interface{}, c *Cond, d Dialect) {
+	wrap := !c.IsLeaf() && !c.Not
+	if wrap {
+		sb.WriteString("(")
+	}
+	c.render(sb, args, d)
+	if wrap {
+		sb.WriteString(")")
+	}
+}
+
+// renderKey 渲染一个 map 形式的条件，切片参数展开为 (?,?,?)
+func renderKey(sb *strings.Builder, args *[]interface{}, key string, value interface{}) {
+	if !strings.Contains(key, "?") {
+		sb.WriteString(key)
+		return
+	}
+	v := reflect.ValueOf(value)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		holders := make([]string, v.Len())
+		for i := range holders {
+			holders[i] = "?"
+			*args = append(*args, v.Index(i).Interface())
+		}
+		sb.WriteString(strings.Replace(key, "?", "("+strings.Join(holders, ",")+")", 1))
+		return
+	}
+	sb.WriteString(key)
+	*args = append(*args, value)
+}
+
+// Where 将全部查询条件渲染为 WHERE 子句(不含 WHERE 关键字)和参数
+// 组合方式与 gorm 的 Where().Or() 链式调用一致：(and 条件 AND Filter) OR or 条件
+// 存在范围条件 Scope 时，整体为 Scope AND (上述条件)
+// 为保证输出稳定，map 中的条件按 key 排序
+func (p *PageInfo) Where() (string, []interface{}) {
+	return p.WhereDialect(DefaultDialect)
+}
+
+// WhereDialect 按指定方言渲染 WHERE 子句，方言只影响自定义操作符
+func (p *PageInfo) WhereDialect(d Dialect) (string, []interface{}) {
+	where, args := p.clientWhere(d)
+	if p.Scope == nil {
+		return where, args
+	}
+	scope, scopeArgs := p.Scope.SQLDialect(d)
+	if !p.Scope.IsLeaf() && !p.Scope.Not && p.Scope.Logic == logicOr {
+		scope = "(" + scope + ")"
+	}
+	if where == "" {
+		return scope, scopeArgs
+	}
+	return scope + " AND (" + where + ")", append(scopeArgs, args...)
+}
+
+// clientWhere 渲染客户端传入的条件
+func (p *PageInfo) clientWhere(d Dialect) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	ands := 0
+	for _, key := range sortedKeys(p.AndParams) {
+		if ands > 0 {
+			sb.WriteString(" AND ")
+		}
+		renderKey(&sb, &args, key, p.AndParams[key])
+		ands++
+	}
+	if p.Filter != nil {
+		if ands > 0 {
+			sb.WriteString(" AND ")
+		}
+		writeGroup(&sb, &args, p.Filter, d)
+		ands++
+	}
+	ors := sortedKeys(p.OrParams)
+	if len(ors) == 0 && p.OrFilter == nil {
+		return sb.String(), args
+	}
+	if ands > 1 {
+		where := sb.String()
+		sb.Reset()
+		sb.WriteString("(" + where + ")")
+	}
+	for i, key := range ors {
+		if i > 0 || ands > 0 {
+			sb.WriteString(" OR ")
+		}
+		renderKey(&sb, &args, key, p.OrParams[key])
+	}
+	if p.OrFilter != nil {
+		if len(ors) > 0 || ands > 0 {
+			sb.WriteString(" OR ")
+		}
+		if p.OrFilter.Logic == logicOr && !p.OrFilter.Not {
+			p.OrFilter.render(&sb, &args, d)
+		} else {
+			writeGroup(&sb, &args, p.OrFilter, d)
+		}
+	}
+	return sb.String(), args
+}
+
+// AddFilter 追加一个条件
+// 不带分组的 AND 标准比较条件直接放入 AndParams，便于已有的 map 写法继续使用，其余条件合并到 Filter
+func (p *PageInfo) AddFilter(c *Cond) {
+	if c == nil {
+		return
+	}
+	if p.AndParams == nil {
+		p.AndParams = make(map[string]interface{})
+	}
+	leaves := []*Cond{c}
+	if c.Logic == logicAnd && !c.Not {
+		leaves = c.Children
+	}
+	var rest []*Cond
+	for _, leaf := range leaves {
+		if leaf.IsLeaf() && !leaf.Not && (leaf.Raw != "" || isSQLOp(leaf.Op)) {
+			if _, ok := p.AndParams[leaf.Key()]; !ok {
+				p.AndParams[leaf.Key()] = leaf.Value
+				continue
+			}
+		}
+		rest = append(rest, leaf)
+	}
+	p.Filter = And(p.Filter, And(rest...))
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
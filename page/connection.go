@@ -0,0 +1,188 @@
+/**
+ * @Time: 2026/10/19 17:41
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"reflect"
+)
+
+const (
+
+	/** Relay 分页默认行数和最大行数，与 rowCount 保持一致 */
+	defaultConnectionSize = 10
+	maxConnectionSize     = 100
+)
+
+// ConnectionArgs Relay 风格的分页参数，first/after 向后翻页，last/before 向前翻页
+type ConnectionArgs struct {
+
+	/** 向后取的行数 */
+	First *int `json:"first,omitempty"`
+
+	/** 从该游标之后开始 */
+	After string `json:"after,omitempty"`
+
+	/** 向前取的行数 */
+	Last *int `json:"last,omitempty"`
+
+	/** 到该游标之前为止 */
+	Before string `json:"before,omitempty"`
+}
+
+// Connection Relay 连接对象
+type Connection struct {
+
+	/** 数据 */
+	Edges []*Edge `json:"edges"`
+
+	/** 翻页信息 */
+	PageInfo *ConnectionPageInfo `json:"pageInfo"`
+
+	/** 总记录数 */
+	TotalCount int64 `json:"totalCount"`
+}
+
+// Edge Relay 连接中的一行
+type Edge struct {
+
+	/** 行数据 */
+	Node interface{} `json:"node"`
+
+	/** 该行的游标 */
+	Cursor string `json:"cursor"`
+}
+
+// ConnectionPageInfo Relay 翻页信息
+type ConnectionPageInfo struct {
+
+	/** 是否还有下一页 */
+	HasNextPage bool `json:"hasNextPage"`
+
+	/** 是否还有上一页 */
+	HasPreviousPage bool `json:"hasPreviousPage"`
+
+	/** 第一行的游标 */
+	StartCursor string `json:"startCursor,omitempty"`
+
+	/** 最后一行的游标 */
+	EndCursor string `json:"endCursor,omitempty"`
+}
+
+// backward 是否向前翻页
+func (a ConnectionArgs) backward() bool {
+	return a.Last != nil
+}
+
+// size 本次要返回的行数
+func (a ConnectionArgs) size() int {
+	n := defaultConnectionSize
+	if a.First != nil {
+		n = *a.First
+	} else if a.Last != nil {
+		n = *a.Last
+	}
+	if n > maxConnectionSize {
+		n = maxConnectionSize
+	}
+	return n
+}
+
+func (a ConnectionArgs) check() error {
+	if a.First != nil && a.Last != nil {
+		return errors.New("first 和 last 不能同时使用")
+	}
+	if (a.First != nil && *a.First < 0) || (a.Last != nil && *a.Last < 0) {
+		return errors.New("first 和 last 不能为负数")
+	}
+	return nil
+}
+
+// ConnectionPage 根据 Relay 参数生成查询参数，使用与 REST 游标分页相同的游标编码和排序规则
+// 返回的查询参数多取一行用于判断是否还有数据，查询结果交给 Connection 处理
+// 总记录数应使用原始的 info 统计，不能带游标条件
+func (k *Keyset) ConnectionPage(info *PageInfo, args ConnectionArgs) (*PageInfo, error) {
+	if err := args.check(); err != nil {
+		return nil, err
+	}
+	var after, before interface{}
+	var err error
+	if args.After != "" {
+		if after, err = DecodeCursor(args.After); err != nil {
+			return nil, err
+		}
+	}
+	if args.Before != "" {
+		if before, err = DecodeCursor(args.Before); err != nil {
+			return nil, err
+		}
+	}
+	// 向前翻页时反向排序，从 before 往前取，结果在 Connection 中再反转回来
+	seek := k
+	start, end := after, before
+	if args.backward() {
+		seek = &Keyset{Column: k.Column, Desc: !k.Desc, Value: k.Value}
+		start, end = before, after
+	}
+	next := seek.Seek(info, start)
+	if end != nil {
+		column := CamelToCase(k.Column)
+		if seek.Desc {
+			next.AddScope(Compare(column, ">", end))
+		} else {
+			next.AddScope(Compare(column, "<", end))
+		}
+	}
+	next.RowCount = args.size() + 1
+	return next, nil
+}
+
+// Connection 将 ConnectionPage 查询到的数据转换为 Relay 连接对象，rows 必须是切片
+func (k *Keyset) Connection(args ConnectionArgs, rows interface{}, total int64) (*Connection, error) {
+	if err := args.check(); err != nil {
+		return nil, err
+	}
+	if k.Value == nil {
+		return nil, errors.New("键集分页必须设置 Keyset.Value")
+	}
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, errors.New("rows 必须是切片")
+	}
+	n := args.size()
+	hasMore := v.Len() > n
+	if hasMore {
+		v = v.Slice(0, n)
+	}
+	edges := make([]*Edge, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		node := v.Index(i).Interface()
+		cursor, err := EncodeCursor(k.Value(node))
+		if err != nil {
+			return nil, err
+		}
+		edges[i] = &Edge{Node: node, Cursor: cursor}
+	}
+	info := &ConnectionPageInfo{}
+	if args.backward() {
+		for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j], edges[i]
+		}
+		info.HasPreviousPage = hasMore
+		info.HasNextPage = args.Before != ""
+	} else {
+		info.HasNextPage = hasMore
+		info.HasPreviousPage = args.After != ""
+	}
+	if len(edges) > 0 {
+		info.StartCursor = edges[0].Cursor
+		info.EndCursor = edges[len(edges)-1].Cursor
+	}
+	return &Connection{Edges: edges, PageInfo: info, TotalCount: total}, nil
+}
@@ -0,0 +1,201 @@
+/**
+ * @Time: 2026/10/19 19:05
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type connectionRow struct {
+	ID int64
+}
+
+var connectionKeyset = &Keyset{
+	Column: "id",
+	Value: func(row interface{}) interface{} {
+		return row.(connectionRow).ID
+	},
+}
+
+// queryConnectionRows 在内存中执行查询参数：只处理 Scope 中 id 上的比较条件、id 排序和行数
+func queryConnectionRows(t *testing.T, rows []connectionRow, info *PageInfo) []connectionRow {
+	var leaves []*Cond
+	var walk func(c *Cond)
+	walk = func(c *Cond) {
+		if c == nil {
+			return
+		}
+		if c.IsLeaf() {
+			leaves = append(leaves, c)
+			return
+		}
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(info.Scope)
+	var out []connectionRow
+	for _, row := range rows {
+		ok := true
+		for _, c := range leaves {
+			v := c.Value.(int64)
+			switch c.Op {
+			case "<":
+				ok = ok && row.ID < v
+			case ">":
+				ok = ok && row.ID > v
+			default:
+				t.Fatalf("unexpected scope %+v", c)
+			}
+		}
+		if ok {
+			out = append(out, row)
+		}
+	}
+	desc := info.OrderStr == "id desc"
+	sort.Slice(out, func(i, j int) bool {
+		return (out[i].ID < out[j].ID) != desc
+	})
+	if len(out) > info.RowCount {
+		out = out[:info.RowCount]
+	}
+	return out
+}
+
+func edgeIDs(c *Connection) []int64 {
+	ids := make([]int64, len(c.Edges))
+	for i, e := range c.Edges {
+		ids[i] = e.Node.(connectionRow).ID
+	}
+	return ids
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestConnectionForward(t *testing.T) {
+	rows := make([]connectionRow, 25)
+	for i := range rows {
+		rows[i] = connectionRow{ID: int64(i + 1)}
+	}
+	args := ConnectionArgs{First: intPtr(10)}
+	var all []int64
+	for page := 0; ; page++ {
+		info, err := connectionKeyset.ConnectionPage(&PageInfo{}, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.RowCount != 11 {
+			t.Fatalf("RowCount = %d, want one extra row", info.RowCount)
+		}
+		conn, err := connectionKeyset.Connection(args, queryConnectionRows(t, rows, info), 25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, edgeIDs(conn)...)
+		if conn.PageInfo.HasPreviousPage != (page > 0) {
+			t.Errorf("page %d: HasPreviousPage = %v", page, conn.PageInfo.HasPreviousPage)
+		}
+		if !conn.PageInfo.HasNextPage {
+			if page != 2 {
+				t.Fatalf("stopped at page %d", page)
+			}
+			break
+		}
+		args.After = conn.PageInfo.EndCursor
+	}
+	if len(all) != 25 || all[0] != 1 || all[24] != 25 {
+		t.Fatalf("ids = %v", all)
+	}
+}
+
+func TestConnectionBackward(t *testing.T) {
+	rows := make([]connectionRow, 25)
+	for i := range rows {
+		rows[i] = connectionRow{ID: int64(i + 1)}
+	}
+	before, _ := EncodeCursor(int64(21))
+	args := ConnectionArgs{Last: intPtr(5), Before: before}
+	info, err := connectionKeyset.ConnectionPage(&PageInfo{}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := connectionKeyset.Connection(args, queryConnectionRows(t, rows, info), 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 向前翻页的结果仍按正序返回
+	if ids := edgeIDs(conn); !reflect.DeepEqual(ids, []int64{16, 17, 18, 19, 20}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	if !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Errorf("pageInfo = %+v", conn.PageInfo)
+	}
+	if start, _ := DecodeCursor(conn.PageInfo.StartCursor); start != int64(16) {
+		t.Errorf("StartCursor = %v", start)
+	}
+}
+
+func TestConnectionPageBounds(t *testing.T) {
+	after, _ := EncodeCursor(5)
+	before, _ := EncodeCursor(9)
+	info, _ := ParsePageParam("status=1&vip=oreq:1")
+	next, err := connectionKeyset.ConnectionPage(info, ConnectionArgs{First: intPtr(3), After: after, Before: before})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 游标条件在最外层，客户端的 or 条件无法绕过
+	where, args := next.Where()
+	if where != "id > ? AND id < ? AND (status = ? OR vip = ?)" || !reflect.DeepEqual(args, []interface{}{int64(5), int64(9), "1", "1"}) {
+		t.Errorf("Where = %s %v", where, args)
+	}
+	if info.Scope != nil {
+		t.Error("ConnectionPage modified the original info")
+	}
+}
+
+func TestConnectionArgsErrors(t *testing.T) {
+	for _, args := range []ConnectionArgs{
+		{First: intPtr(1), Last: intPtr(1)},
+		{First: intPtr(-1)},
+		{Last: intPtr(-1)},
+		{After: "not base64!"},
+	} {
+		if _, err := connectionKeyset.ConnectionPage(&PageInfo{}, args); err == nil {
+			t.Errorf("%+v: accepted", args)
+		}
+	}
+	if _, err := connectionKeyset.Connection(ConnectionArgs{}, "rows", 0); err == nil {
+		t.Error("non-slice rows accepted")
+	}
+	if _, err := (&Keyset{Column: "id"}).Connection(ConnectionArgs{}, []connectionRow{}, 0); err == nil {
+		t.Error("Keyset without Value accepted")
+	}
+	if got := (ConnectionArgs{First: intPtr(1000)}).size(); got != maxConnectionSize {
+		t.Errorf("size = %d, want %d", got, maxConnectionSize)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, value := range []interface{}{int64(42), 1.5, "2022-01-01 10:00:00", true} {
+		cursor, err := EncodeCursor(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeCursor(cursor)
+		if err != nil || got != value {
+			t.Errorf("DecodeCursor(EncodeCursor(%v)) = %v %v", value, got, err)
+		}
+	}
+	for _, cursor := range []string{"", "  ", "%%%", "bm90IGpzb24"} {
+		if _, err := DecodeCursor(cursor); err == nil {
+			t.Errorf("DecodeCursor(%q) accepted", cursor)
+		}
+	}
+}
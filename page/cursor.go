@@ -0,0 +1,86 @@
+/**
+ * @Time: 2026/10/19 17:34
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Keyset 键集(游标)分页配置，按一个唯一且单调的列翻页，
+// 翻页期间有数据插入或删除也不会出现重复或遗漏，且没有深分页的 offset 开销
+type Keyset struct {
+
+	/** 游标列，必须唯一，例如 id，支持驼峰写法 */
+	Column string
+
+	/** 是否按游标列倒序 */
+	Desc bool
+
+	/** 从一行数据中取出游标列的值 */
+	Value func(row interface{}) interface{}
+}
+
+// Seek 基于 info 生成"在 after 之后"的一页查询参数，after 为 nil 时表示第一页
+// 返回的是副本，排序被替换为游标列排序，当前页固定为 1
+// 游标条件追加到 Scope，与客户端条件整体 AND 连接，or 条件无法绕过游标
+func (k *Keyset) Seek(info *PageInfo, after interface{}) *PageInfo {
+	column := CamelToCase(k.Column)
+	next := info.Clone()
+	next.Current = 1
+	next.Skip = 0
+	if k.Desc {
+		next.OrderStr = column + " desc"
+		if after != nil {
+			next.AddScope(Compare(column, "<", after))
+		}
+	} else {
+		next.OrderStr = column + " asc"
+		if after != nil {
+			next.AddScope(Compare(column, ">", after))
+		}
+	}
+	return next
+}
+
+// EncodeCursor 将游标列的值编码成对客户端不透明的字符串
+func EncodeCursor(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor 解析 EncodeCursor 生成的游标，整数还原为 int64，其余数字为 float64
+func DecodeCursor(cursor string) (interface{}, error) {
+	cursor = strings.TrimSpace(cursor)
+	if cursor == "" {
+		return nil, errors.New("游标不能为空")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("游标格式错误：" + err.Error())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var value interface{}
+	if err = decoder.Decode(&value); err != nil {
+		return nil, errors.New("游标格式错误：" + err.Error())
+	}
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,270 @@
+/**
+ * @Time: 2026/10/19 17:34
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+const (
+
+	/** 迭代器默认每批行数 */
+	defaultBatchSize = 500
+)
+
+// FetchFunc 按分页参数查询一页数据，rows 必须是切片，例如 []User 或 []*User
+type FetchFunc func(ctx context.Context, info *PageInfo) (rows interface{}, err error)
+
+// IteratorOption 迭代器配置项
+type IteratorOption func(it *Iterator)
+
+// WithBatchSize 每批查询的行数，不受 rowCount 的上限约束
+func WithBatchSize(size int) IteratorOption {
+	return func(it *Iterator) {
+		if size > 0 {
+			it.batchSize = size
+		}
+	}
+}
+
+// WithKeyset 使用键集分页遍历，导出期间数据有变化也能保持一致
+func WithKeyset(keyset *Keyset) IteratorOption {
+	return func(it *Iterator) {
+		it.keyset = keyset
+	}
+}
+
+// WithCursor 从指定游标之后继续遍历，仅键集分页有效，游标来自 Iterator.Cursor
+func WithCursor(cursor string) IteratorOption {
+	return func(it *Iterator) {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			it.err = err
+			return
+		}
+		it.after = after
+	}
+}
+
+// WithPrefetch 后台预取的页数，缓冲区满时停止查询，等待消费者跟上
+// 默认为 0，即消费完一页后才查询下一页
+func WithPrefetch(pages int) IteratorOption {
+	return func(it *Iterator) {
+		if pages > 0 {
+			it.prefetch = pages
+		}
+	}
+}
+
+// Iterator 逐页遍历所有数据的迭代器，内存中最多只保留 1+prefetch 页
+//
+//	it := page.NewIterator(ctx, info, fetch, page.WithBatchSize(1000))
+//	defer it.Close()
+//	for it.Next() {
+//		row := it.Row().(*User)
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	info      *PageInfo
+	fetch     FetchFunc
+	batchSize int
+	keyset    *Keyset
+	prefetch  int
+
+	/** 生产者状态：下一页页码和键集游标值 */
+	current  int
+	after    interface{}
+	finished bool
+
+	/** 预取模式下的页缓冲 */
+	pages chan fetchResult
+
+	/** 消费者状态 */
+	rows  reflect.Value
+	index int
+	row   interface{}
+	err   error
+	done  bool
+}
+
+type fetchResult struct {
+	rows reflect.Value
+	err  error
+}
+
+// NewIterator 创建迭代器，info 不会被修改
+func NewIterator(ctx context.Context, info *PageInfo, fetch FetchFunc, opts ...IteratorOption) *Iterator {
+	if info == nil {
+		info = &PageInfo{}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	it := &Iterator{
+		ctx:       ctx,
+		cancel:    cancel,
+		info:      info.Clone(),
+		fetch:     fetch,
+		batchSize: defaultBatchSize,
+		current:   1,
+		index:     -1,
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+	if it.err == nil && it.prefetch > 0 {
+		it.pages = make(chan fetchResult, it.prefetch)
+		go it.produce()
+	}
+	return it
+}
+
+// Next 移动到下一行，没有数据、出错或上下文取消时返回 false
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	for it.index+1 >= it.length() {
+		rows, ok, err := it.nextPage()
+		if err != nil {
+			it.err = err
+			it.release()
+			return false
+		}
+		if !ok {
+			it.done = true
+			it.release()
+			return false
+		}
+		it.rows = rows
+		it.index = -1
+	}
+	it.index++
+	it.row = it.rows.Index(it.index).Interface()
+	return true
+}
+
+// Row 当前行
+func (it *Iterator) Row() interface{} {
+	return it.row
+}
+
+// Cursor 当前行的游标，可通过 WithCursor 从此处断点续传，非键集分页时返回空串
+func (it *Iterator) Cursor() string {
+	if it.keyset == nil || it.keyset.Value == nil || it.row == nil {
+		return ""
+	}
+	cursor, err := EncodeCursor(it.keyset.Value(it.row))
+	if err != nil {
+		return ""
+	}
+	return cursor
+}
+
+// Err 遍历过程中的错误
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close 提前结束遍历，释放后台预取
+func (it *Iterator) Close() {
+	it.done = true
+	it.release()
+}
+
+func (it *Iterator) release() {
+	it.cancel()
+	it.rows = reflect.Value{}
+}
+
+func (it *Iterator) length() int {
+	if !it.rows.IsValid() {
+		return 0
+	}
+	return it.rows.Len()
+}
+
+// nextPage 取下一页，ok 为 false 表示已经没有数据
+func (it *Iterator) nextPage() (rows reflect.Value, ok bool, err error) {
+	if it.pages == nil {
+		if err = it.ctx.Err(); err != nil {
+			return rows, false, err
+		}
+		rows, err = it.fetchPage()
+		return rows, err == nil && rows.IsValid() && rows.Len() > 0, err
+	}
+	select {
+	case <-it.ctx.Done():
+		return rows, false, it.ctx.Err()
+	case res, open := <-it.pages:
+		if !open {
+			return rows, false, nil
+		}
+		return res.rows, res.err == nil, res.err
+	}
+}
+
+// produce 后台预取，缓冲区满时阻塞，形成背压
+func (it *Iterator) produce() {
+	defer close(it.pages)
+	for {
+		rows, err := it.fetchPage()
+		if err == nil && (!rows.IsValid() || rows.Len() == 0) {
+			return
+		}
+		select {
+		case it.pages <- fetchResult{rows: rows, err: err}:
+		case <-it.ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// fetchPage 查询一页并推进生产者状态，只在一个 goroutine 中调用
+func (it *Iterator) fetchPage() (reflect.Value, error) {
+	if it.finished {
+		return reflect.Value{}, nil
+	}
+	var info *PageInfo
+	if it.keyset != nil {
+		info = it.keyset.Seek(it.info, it.after)
+	} else {
+		info = it.info.Clone()
+		info.Current = it.current
+		info.Skip = 0
+	}
+	info.RowCount = it.batchSize
+	result, err := it.fetch(it.ctx, info)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	rows := reflect.ValueOf(result)
+	if rows.Kind() == reflect.Ptr {
+		rows = rows.Elem()
+	}
+	if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array {
+		return reflect.Value{}, errors.New("fetch 返回的数据必须是切片")
+	}
+	if rows.Len() < it.batchSize {
+		it.finished = true
+	}
+	if rows.Len() > 0 {
+		it.current++
+		if it.keyset != nil {
+			if it.keyset.Value == nil {
+				return reflect.Value{}, errors.New("键集分页必须设置 Keyset.Value")
+			}
+			it.after = it.keyset.Value(rows.Index(rows.Len() - 1).Interface())
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,476 @@
+/**
+ * @Time: 2026/10/19 17:37
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// odataOps OData 比较符与 SQL 比较符的对应关系
+var odataOps = map[string]string{
+	"eq": "=",
+	"ne": "<>",
+	"gt": ">",
+	"ge": ">=",
+	"lt": "<",
+	"le": "<=",
+}
+
+// ODataParam 按 OData 约定获取url查询参数，并附加已注册的范围条件，解析失败时返回 nil
+func ODataParam(c *gin.Context) *PageInfo {
+	pageInfo, err := ParseOData(c.Request.URL.RawQuery)
+	if err != nil {
+		log.Println("OData参数解析异常：" + err.Error())
+		return nil
+	}
+	if err = ApplyScopes(c, pageInfo); err != nil {
+		log.Println(err.Error())
+		return nil
+	}
+	return pageInfo
+}
+
+// IsOData url查询参数中是否含有 $filter $orderby $top $skip $count 之一
+func IsOData(rawQuery string) bool {
+	s := queryScanner{rest: rawQuery}
+	for key, _, ok := s.next(); ok; key, _, ok = s.next() {
+		if isODataKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOData 解析 OData 查询参数的子集
+// $filter 支持 eq ne gt ge lt le and or not 括号以及 contains startswith endswith 函数
+// $orderby 支持多个字段和 asc desc，$top 对应每页行数，不能超过 100
+// $skip 写入 Skip，查询时使用 Offset，Current 取 $skip 所在的页
+// $count 只做校验，分页结果中的 total 始终会返回
+// $filter 中的括号和 not 最多嵌套 MaxFilterDepth 层
+// 关联路径例如 customer/city 只能通过 Schema 白名单查询，这里出现时返回错误
+func ParseOData(rawQuery string) (*PageInfo, error) {
+	pageInfo, err := parseOData(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRelationPaths(pageInfo); err != nil {
+		return nil, err
+	}
+	return pageInfo, nil
+}
+
+// parseOData 解析 OData 查询参数，保留关联路径，由 Schema.Apply 校验和转换
+func parseOData(rawQuery string) (*PageInfo, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	pageInfo := &PageInfo{
+		Current:   1,
+		RowCount:  10,
+		AndParams: make(map[string]interface{}),
+		OrParams:  make(map[string]interface{}),
+	}
+	if top := values.Get("$top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 {
+			return nil, errors.New("$top 必须是正整数")
+		}
+		if n > 100 {
+			return nil, errors.New("$top 不能超过 100")
+		}
+		pageInfo.RowCount = n
+	}
+	if skip := values.Get("$skip"); skip != "" {
+		n, err := strconv.Atoi(skip)
+		if err != nil || n < 0 {
+			return nil, errors.New("$skip 必须是非负整数")
+		}
+		pageInfo.Skip = n
+		pageInfo.Current = n/pageInfo.RowCount + 1
+	}
+	if count := values.Get("$count"); count != "" && count != "true" && count != "false" {
+		return nil, errors.New("$count 只能是 true 或 false")
+	}
+	if orderBy := values.Get("$orderby"); orderBy != "" {
+		order, err := parseODataOrderBy(orderBy)
+		if err != nil {
+			return nil, err
+		}
+		pageInfo.OrderStr = order
+	}
+	if filter := values.Get("$filter"); filter != "" {
+		cond, err := ParseODataFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		pageInfo.AddFilter(cond)
+	}
+	return pageInfo, nil
+}
+
+// parseODataOrderBy 将 "createTime desc,id" 转换为 OrderStr 的格式 "create_time desc,id asc"
+func parseODataOrderBy(orderBy string) (string, error) {
+	items := strings.Split(orderBy, ",")
+	orders := make([]string, 0, len(items))
+	for _, item := range items {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || len(fields) > 2 {
+			return "", errors.New("$orderby 格式错误：" + item)
+		}
+		name := strings.ReplaceAll(fields[0], "/", ".")
+		for i := 0; i < len(name); i++ {
+			if !isSelectorChar(name[i]) {
+				return "", errors.New("$orderby 字段名不合法：" + fields[0])
+			}
+		}
+		direction := "asc"
+		if len(fields) == 2 {
+			direction = strings.ToLower(fields[1])
+			if direction != "asc" && direction != "desc" {
+				return "", errors.New("$orderby 排序方向只能是 asc 或 desc：" + item)
+			}
+		}
+		orders = append(orders, CamelToCase(name)+" "+direction)
+	}
+	return strings.Join(orders, ","), nil
+}
+
+// ParseODataFilter 解析 OData $filter 表达式
+func ParseODataFilter(expr string) (*Cond, error) {
+	p := &odataParser{lexer: odataLexer{expr: expr}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == odataEOF {
+		return nil, p.errorf(p.tok.pos, "表达式不能为空")
+	}
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != odataEOF {
+		return nil, p.errorf(p.tok.pos, "存在多余的内容 %q", p.tok.text)
+	}
+	return c, nil
+}
+
+const (
+	odataEOF = iota
+	odataIdent
+	odataString
+	odataNumber
+	odataLParen
+	odataRParen
+	odataComma
+)
+
+type odataToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+type odataLexer struct {
+	expr string
+	pos  int
+}
+
+func (l *odataLexer) next() (odataToken, error) {
+	for l.pos < len(l.expr) && (l.expr[l.pos] == ' ' || l.expr[l.pos] == '\t') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.expr) {
+		return odataToken{kind: odataEOF, pos: start}, nil
+	}
+	ch := l.expr[l.pos]
+	switch {
+	case ch == '(':
+		l.pos++
+		return odataToken{kind: odataLParen, text: "(", pos: start}, nil
+	case ch == ')':
+		l.pos++
+		return odataToken{kind: odataRParen, text: ")", pos: start}, nil
+	case ch == ',':
+		l.pos++
+		return odataToken{kind: odataComma, text: ",", pos: start}, nil
+	case ch == '\'':
+		// 字符串中的单引号写成两个单引号
+		var sb strings.Builder
+		l.pos++
+		for l.pos < len(l.expr) {
+			c := l.expr[l.pos]
+			l.pos++
+			if c != '\'' {
+				sb.WriteByte(c)
+				continue
+			}
+			if l.pos < len(l.expr) && l.expr[l.pos] == '\'' {
+				sb.WriteByte('\'')
+				l.pos++
+				continue
+			}
+			return odataToken{kind: odataString, text: sb.String(), pos: start}, nil
+		}
+		return odataToken{}, &SyntaxError{Expr: l.expr, Pos: start, Msg: "引号未闭合"}
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		l.pos++
+		for l.pos < len(l.expr) && strings.IndexByte("0123456789.eE+-:TZ", l.expr[l.pos]) >= 0 {
+			l.pos++
+		}
+		return odataToken{kind: odataNumber, text: l.expr[start:l.pos], pos: start}, nil
+	case isSelectorChar(ch) || ch == '/':
+		for l.pos < len(l.expr) && (isSelectorChar(l.expr[l.pos]) || l.expr[l.pos] == '/') {
+			l.pos++
+		}
+		return odataToken{kind: odataIdent, text: l.expr[start:l.pos], pos: start}, nil
+	}
+	return odataToken{}, &SyntaxError{Expr: l.expr, Pos: start, Msg: fmt.Sprintf("无法识别的字符 %q", ch)}
+}
+
+type odataParser struct {
+	lexer odataLexer
+	tok   odataToken
+	depth int
+}
+
+func (p *odataParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *odataParser) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.lexer.expr, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword 当前 token 是否为指定关键字，关键字不区分大小写
+func (p *odataParser) isKeyword(word string) bool {
+	return p.tok.kind == odataIdent && strings.EqualFold(p.tok.text, word)
+}
+
+// enter 进入一层括号或 not，超过 MaxFilterDepth 层时返回错误
+func (p *odataParser) enter() error {
+	if p.depth >= MaxFilterDepth {
+		return p.errorf(p.tok.pos, "括号或 not 嵌套超过 %d 层", MaxFilterDepth)
+	}
+	p.depth++
+	return nil
+}
+
+func (p *odataParser) expect(kind int, what string) error {
+	if p.tok.kind != kind {
+		return p.errorf(p.tok.pos, "缺少 %s", what)
+	}
+	return p.advance()
+}
+
+// parseOr or = and { "or" and }
+func (p *odataParser) parseOr() (*Cond, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	conds := []*Cond{first}
+	for p.isKeyword("or") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		c, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return Or(conds...), nil
+}
+
+// parseAnd and = unary { "and" unary }
+func (p *odataParser) parseAnd() (*Cond, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	conds := []*Cond{first}
+	for p.isKeyword("and") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		c, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return And(conds...), nil
+}
+
+// parseUnary unary = "not" unary | primary
+func (p *odataParser) parseUnary() (*Cond, error) {
+	if p.isKeyword("not") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		c, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		p.depth--
+		return Not(c), nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary primary = "(" or ")" | function | member op literal
+func (p *odataParser) parsePrimary() (*Cond, error) {
+	switch p.tok.kind {
+	case odataLParen:
+		open := p.tok.pos
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.depth--
+		if p.tok.kind != odataRParen {
+			return nil, p.errorf(open, "括号未闭合")
+		}
+		return c, p.advance()
+	case odataIdent:
+	default:
+		return nil, p.errorf(p.tok.pos, "缺少条件")
+	}
+	name := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == odataLParen {
+		return p.parseFunction(name)
+	}
+	column := odataColumn(name.text)
+	if p.tok.kind != odataIdent {
+		return nil, p.errorf(p.tok.pos, "缺少比较符")
+	}
+	op, ok := odataOps[strings.ToLower(p.tok.text)]
+	if !ok {
+		return nil, p.errorf(p.tok.pos, "不支持的比较符 %s", p.tok.text)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	valueTok := p.tok
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		switch op {
+		case "=":
+			return Compare(column, "IS NULL", nil), nil
+		case "<>":
+			return Compare(column, "IS NOT NULL", nil), nil
+		default:
+			return nil, p.errorf(valueTok.pos, "null 只能与 eq 或 ne 比较")
+		}
+	}
+	return Compare(column, op, value), nil
+}
+
+// parseFunction function = name "(" member "," string ")"
+func (p *odataParser) parseFunction(name odataToken) (*Cond, error) {
+	fn := strings.ToLower(name.text)
+	if fn != "contains" && fn != "startswith" && fn != "endswith" {
+		return nil, p.errorf(name.pos, "不支持的函数 %s", name.text)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != odataIdent {
+		return nil, p.errorf(p.tok.pos, "%s 的第一个参数必须是字段名", fn)
+	}
+	column := odataColumn(p.tok.text)
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(odataComma, ","); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != odataString {
+		return nil, p.errorf(p.tok.pos, "%s 的第二个参数必须是字符串", fn)
+	}
+	value := escapeLike(p.tok.text)
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(odataRParen, ")"); err != nil {
+		return nil, err
+	}
+	switch fn {
+	case "contains":
+		value = "%" + value + "%"
+	case "startswith":
+		value = value + "%"
+	default:
+		value = "%" + value
+	}
+	return Compare(column, "LIKE", value), nil
+}
+
+// parseLiteral 读取字面量：字符串、数字、true false null，日期时间按字符串处理
+func (p *odataParser) parseLiteral() (interface{}, error) {
+	tok := p.tok
+	var value interface{}
+	switch tok.kind {
+	case odataString:
+		value = tok.text
+	case odataNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			value = i
+		} else if f, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			value = f
+		} else {
+			value = tok.text
+		}
+	case odataIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			return nil, p.errorf(tok.pos, "比较值 %s 不合法，字符串需要用单引号包裹", tok.text)
+		}
+	default:
+		return nil, p.errorf(tok.pos, "缺少比较值")
+	}
+	return value, p.advance()
+}
+
+// odataColumn OData 的导航路径 a/b 转换为 a.b，驼峰转下划线
+func odataColumn(name string) string {
+	return CamelToCase(strings.ReplaceAll(name, "/", "."))
+}
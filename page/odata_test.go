@@ -0,0 +1,110 @@
+/**
+ * @Time: 2026/10/19 18:35
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseODataFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		args []interface{}
+	}{
+		{"name eq 'abc' and (age ge 18 or vip eq true)", "name = ? AND (age >= ? OR vip = ?)", []interface{}{"abc", int64(18), true}},
+		{"not (status ne 1)", "NOT (status <> ?)", []interface{}{int64(1)}},
+		{"contains(userName,'a_b')", "user_name LIKE ?", []interface{}{`%a\_b%`}},
+		{"startswith(name,'it''s')", "name LIKE ?", []interface{}{"it's%"}},
+		{"price lt 9.5 and id gt -3", "price < ? AND id > ?", []interface{}{9.5, int64(-3)}},
+		{"deletedAt eq null", "deleted_at IS NULL", nil},
+	}
+	for _, tt := range tests {
+		c, err := ParseODataFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		sql, args := c.SQL()
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %s %#v, want %s %#v", tt.expr, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestParseODataFilterDepth(t *testing.T) {
+	nested := strings.Repeat("(", MaxFilterDepth) + "a eq 1" + strings.Repeat(")", MaxFilterDepth)
+	if _, err := ParseODataFilter(nested); err != nil {
+		t.Fatalf("depth %d: %v", MaxFilterDepth, err)
+	}
+	if _, err := ParseODataFilter(strings.Repeat("not ", MaxFilterDepth) + "a eq 1"); err != nil {
+		t.Fatalf("not depth %d: %v", MaxFilterDepth, err)
+	}
+	for _, expr := range []string{
+		"(" + nested + ")",
+		strings.Repeat("(", 1<<20),
+		strings.Repeat("not ", 1<<20),
+		strings.Repeat("not (", 1<<19),
+	} {
+		_, err := ParseODataFilter(expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("len %d: err = %v, want *SyntaxError", len(expr), err)
+		}
+	}
+}
+
+func TestParseODataPaging(t *testing.T) {
+	tests := []struct {
+		query    string
+		current  int
+		rowCount int
+		offset   int
+	}{
+		{"$top=10&$skip=20", 3, 10, 20},
+		// $skip 不是 $top 的整数倍时按行数跳过
+		{"$top=10&$skip=5", 1, 10, 5},
+		{"$top=10&$skip=25", 3, 10, 25},
+		{"$skip=7", 1, 10, 7},
+		{"$top=100", 1, 100, 0},
+	}
+	for _, tt := range tests {
+		info, err := ParseOData(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if info.Current != tt.current || info.RowCount != tt.rowCount || info.Offset() != tt.offset {
+			t.Errorf("%s: current/rowCount/offset = %d/%d/%d, want %d/%d/%d", tt.query,
+				info.Current, info.RowCount, info.Offset(), tt.current, tt.rowCount, tt.offset)
+		}
+	}
+	for _, query := range []string{"$top=101", "$top=0", "$top=x", "$skip=-1", "$count=yes"} {
+		if _, err := ParseOData(query); err == nil {
+			t.Errorf("%s: accepted", query)
+		}
+	}
+}
+
+func TestParseODataOrderBy(t *testing.T) {
+	info, err := ParseOData("$orderby=createTime desc,id&$filter=status eq 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.OrderStr != "create_time desc,id asc" {
+		t.Errorf("OrderStr = %q", info.OrderStr)
+	}
+	if where, _ := info.Where(); where != "status = ?" {
+		t.Errorf("Where = %q", where)
+	}
+	if _, err = ParseOData("$orderby=id up"); err == nil {
+		t.Error("invalid direction accepted")
+	}
+	if _, err = ParseOData("$filter=customer/city eq 'x'"); err == nil {
+		t.Error("relation path accepted without a Schema")
+	}
+}
@@ -0,0 +1,425 @@
+/**
+ * @Time: 2026/10/19 17:35
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+// OpenAPIParameter OpenAPI 3 参数对象
+type OpenAPIParameter struct {
+
+	/** 参数名 */
+	Name string `json:"name" yaml:"name"`
+
+	/** 参数位置，分页参数固定为 query */
+	In string `json:"in" yaml:"in"`
+
+	/** 说明 */
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	/** 是否必填 */
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	/** 参数值结构 */
+	Schema *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+
+	/** 示例 */
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+
+	/** 按操作符列出的示例 */
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+// OpenAPIExample OpenAPI 3 示例对象
+type OpenAPIExample struct {
+
+	/** 摘要 */
+	Summary string `json:"summary,omitempty" yaml:"summary,omitempty"`
+
+	/** 示例值 */
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+// OpenAPISchema OpenAPI 3 数据结构对象，仅包含生成分页文档需要的属性
+type OpenAPISchema struct {
+
+	/** 标题 */
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+
+	/** 数据类型 */
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	/** 数据格式，例如 int64 date-time */
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	/** 说明 */
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	/** 正则约束 */
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	/** 枚举值 */
+	Enum []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+
+	/** 默认值 */
+	Default interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+
+	/** 最小值 */
+	Minimum *float64 `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+
+	/** 最大值 */
+	Maximum *float64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+
+	/** 数组元素结构 */
+	Items *OpenAPISchema `json:"items,omitempty" yaml:"items,omitempty"`
+
+	/** 对象属性 */
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+
+	/** 是否允许任意属性，interface{} 和 map 使用 */
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+
+	/** 满足其中任意一个结构即可 */
+	AnyOf []*OpenAPISchema `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+
+	/** 示例 */
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// OpenAPIParameters 生成列表接口的全部查询参数：current rowCount orderStr filter preset 以及每个字段
+// OData 风格的参数见 OpenAPIODataParameters
+func (s *Schema) OpenAPIParameters() []*OpenAPIParameter {
+	params := []*OpenAPIParameter{
+		{
+			Name:        "current",
+			In:          "query",
+			Description: "当前页，从 1 开始",
+			Schema:      &OpenAPISchema{Type: TypeInteger, Minimum: float(1), Default: 1},
+			Example:     1,
+		},
+		{
+			Name:        "rowCount",
+			In:          "query",
+			Description: "每页行数，超过 100 按 100 处理",
+			Schema:      &OpenAPISchema{Type: TypeInteger, Minimum: float(1), Maximum: float(100), Default: 10},
+			Example:     10,
+		},
+	}
+	if order := s.orderParameter(); order != nil {
+		params = append(params, order)
+	}
+	params = append(params, s.filterParameter(), &OpenAPIParameter{
+		Name:        "preset",
+		In:          "query",
+		Description: "已保存的查询预设名称，预设的条件、排序和每页行数与 url 中的其余参数合并，url 中的参数优先",
+		Schema:      &OpenAPISchema{Type: TypeString},
+	})
+	if s == nil {
+		return params
+	}
+	for i := range s.Fields {
+		params = append(params, s.Fields[i].openAPIParameter())
+	}
+	for _, relation := range s.Relations {
+		for _, f := range relation.Fields {
+			f.Name = relation.Name + "." + f.Name
+			params = append(params, f.openAPIParameter())
+		}
+	}
+	return params
+}
+
+// OpenAPIODataParameters 生成按 OData 约定查询时的参数：$filter $orderby $top $skip $count
+func (s *Schema) OpenAPIODataParameters() []*OpenAPIParameter {
+	example := "name eq 'abc'"
+	if f := s.firstField(); f != nil {
+		example = fmt.Sprintf("%s ge %s", f.Name, odataLiteral(f))
+	}
+	params := []*OpenAPIParameter{
+		{
+			Name:        "$filter",
+			In:          "query",
+			Description: "OData 过滤表达式，支持 eq ne gt ge lt le and or not 括号以及 contains startswith endswith 函数，括号和 not 最多嵌套 " + strconv.Itoa(MaxFilterDepth) + " 层",
+			Schema:      &OpenAPISchema{Type: TypeString},
+			Example:     example,
+		},
+	}
+	var names []string
+	if s != nil {
+		for _, f := range s.Fields {
+			if f.Sortable {
+				names = append(names, f.Name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		params = append(params, &OpenAPIParameter{
+			Name:        "$orderby",
+			In:          "query",
+			Description: "排序，逗号分隔的 字段名 [asc|desc]，可排序字段：" + strings.Join(names, ", "),
+			Schema: &OpenAPISchema{
+				Type:    TypeString,
+				Pattern: "^(" + strings.Join(names, "|") + ")( (asc|desc))?(,(" + strings.Join(names, "|") + ")( (asc|desc))?)*$",
+			},
+			Example: names[0] + " desc",
+		})
+	}
+	return append(params,
+		&OpenAPIParameter{
+			Name:        "$top",
+			In:          "query",
+			Description: "返回的行数，不能超过 100",
+			Schema:      &OpenAPISchema{Type: TypeInteger, Minimum: float(1), Maximum: float(100), Default: 10},
+			Example:     10,
+		},
+		&OpenAPIParameter{
+			Name:        "$skip",
+			In:          "query",
+			Description: "跳过的行数，可以不是 $top 的整数倍",
+			Schema:      &OpenAPISchema{Type: TypeInteger, Minimum: float(0), Default: 0},
+			Example:     0,
+		},
+		&OpenAPIParameter{
+			Name:        "$count",
+			In:          "query",
+			Description: "是否返回总数，分页结果中的 total 始终会返回",
+			Schema:      &OpenAPISchema{Type: TypeBoolean, Enum: []interface{}{true, false}},
+		},
+	)
+}
+
+// OpenAPIResponse 生成列表接口的响应结构：result.Response 包裹 PageBean，rows 为 Model 数组
+func (s *Schema) OpenAPIResponse() *OpenAPISchema {
+	row := &OpenAPISchema{Type: "object", AdditionalProperties: true}
+	if s != nil && s.Model != nil {
+		row = OpenAPISchemaOf(s.Model)
+	}
+	bean := OpenAPISchemaOf(PageBean{})
+	bean.Properties["rows"] = &OpenAPISchema{Type: "array", Items: row, Description: "每行的数据"}
+	resp := OpenAPISchemaOf(result.Response{})
+	resp.Properties["content"] = bean
+	return resp
+}
+
+// orderParameter 排序参数，没有可排序字段时返回 nil
+func (s *Schema) orderParameter() *OpenAPIParameter {
+	var names []string
+	if s != nil {
+		for _, f := range s.Fields {
+			if f.Sortable {
+				names = append(names, f.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return &OpenAPIParameter{
+		Name:        "orderStr",
+		In:          "query",
+		Description: "排序，字段名后跟 " + pd + " 降序或 " + pa + " 升序，可连写多个，可排序字段：" + strings.Join(names, ", "),
+		Schema: &OpenAPISchema{
+			Type:    TypeString,
+			Pattern: "^((" + strings.Join(names, "|") + ")(" + pd + "|" + pa + "))+$",
+		},
+		Example: names[0] + pd,
+	}
+}
+
+// filterParameter RSQL/FIQL 过滤表达式参数
+func (s *Schema) filterParameter() *OpenAPIParameter {
+	example := "name==abc*;(age=ge=18,vip==true)"
+	if f := s.firstField(); f != nil {
+		example = fmt.Sprintf("%s=ge=%v", f.Name, f.exampleValue())
+	}
+	return &OpenAPIParameter{
+		Name:        "filter",
+		In:          "query",
+		Description: "RSQL/FIQL 过滤表达式，; 表示且，, 表示或，括号分组，括号最多嵌套 " + strconv.Itoa(MaxFilterDepth) + " 层，== 和 != 的值中 * 为通配符",
+		Schema:      &OpenAPISchema{Type: TypeString},
+		Example:     example,
+	}
+}
+
+// firstField 第一个可查询的字段，用于生成示例
+func (s *Schema) firstField() *Field {
+	if s == nil || len(s.Fields) == 0 {
+		return nil
+	}
+	return &s.Fields[0]
+}
+
+// exampleValue 字段的示例值
+func (f *Field) exampleValue() interface{} {
+	if f.Example != nil {
+		return f.Example
+	}
+	return exampleOf(f.Type)
+}
+
+// odataLiteral 字段示例值的 OData 字面量，字符串和日期需要单引号
+func odataLiteral(f *Field) string {
+	switch f.Type {
+	case TypeInteger, TypeNumber, TypeBoolean:
+		return fmt.Sprint(f.exampleValue())
+	}
+	return "'" + strings.ReplaceAll(fmt.Sprint(f.exampleValue()), "'", "''") + "'"
+}
+
+// openAPIParameter 单个字段的参数，值的格式为 操作符:值
+// 操作符是值的前缀，enum 只能约束整个值，这里用 anyOf 为每个操作符列出一个分支，
+// 分支的 title 为操作符，pattern 约束前缀，不带操作符的值按等于处理
+func (f *Field) openAPIParameter() *OpenAPIParameter {
+	ops := f.ops()
+	typ, format := f.Type, ""
+	if typ == "" {
+		typ = TypeString
+	}
+	if typ == TypeDate || typ == TypeDateTime {
+		typ, format = TypeString, f.Type
+	}
+	example := f.exampleValue()
+	examples := make(map[string]*OpenAPIExample, len(ops))
+	branches := []*OpenAPISchema{{Type: typ, Format: format, Description: "不带操作符，按等于处理"}}
+	for _, op := range ops {
+		summary := op
+		if operator := LookupOperator(op); operator != nil && operator.Description != "" {
+			summary = operator.Description
+		}
+		examples[op] = &OpenAPIExample{Summary: summary, Value: fmt.Sprintf("%s:%v", op, example)}
+		branches = append(branches, &OpenAPISchema{
+			Title:       op,
+			Type:        TypeString,
+			Description: summary,
+			Pattern:     "^" + regexp.QuoteMeta(op) + ":.+$",
+		})
+	}
+	desc := f.Description
+	if desc != "" {
+		desc += "；"
+	}
+	desc += "值的格式为 操作符:值，不带操作符时按等于处理，允许的操作符：" + strings.Join(ops, ", ")
+	return &OpenAPIParameter{
+		Name:        f.Name,
+		In:          "query",
+		Description: desc,
+		Schema: &OpenAPISchema{
+			Description: "值的类型：" + typ,
+			AnyOf:       branches,
+		},
+		Examples: examples,
+	}
+}
+
+// OpenAPISchemaOf 根据结构体的 json 标签生成数据结构
+func OpenAPISchemaOf(v interface{}) *OpenAPISchema {
+	return schemaOfType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaOfType(t reflect.Type, seen map[reflect.Type]bool) *OpenAPISchema {
+	if t == nil {
+		return &OpenAPISchema{Type: "object", AdditionalProperties: true}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &OpenAPISchema{Type: TypeString, Format: TypeDateTime}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: TypeInteger, Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: TypeInteger, Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: TypeNumber, Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: TypeNumber, Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: TypeString}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: TypeString, Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: schemaOfType(t.Elem(), seen)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: schemaOfType(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &OpenAPISchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+		addProperties(s, t, seen)
+		return s
+	default:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: true}
+	}
+}
+
+// addProperties 按 encoding/json 的规则展开字段，匿名嵌入的结构体字段提升到外层
+func addProperties(s *OpenAPISchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(s, ft, seen)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = schemaOfType(field.Type, seen)
+	}
+}
+
+// exampleOf 按类型给出默认示例值
+func exampleOf(typ string) interface{} {
+	switch typ {
+	case TypeInteger:
+		return 18
+	case TypeNumber:
+		return 9.9
+	case TypeBoolean:
+		return true
+	case TypeDate:
+		return "2022-03-04"
+	case TypeDateTime:
+		return "2022-03-04 19:02:00"
+	default:
+		return "abc"
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
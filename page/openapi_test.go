@@ -0,0 +1,149 @@
+/**
+ * @Time: 2026/10/19 18:25
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type openAPIUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var openAPISchema = &Schema{
+	Table: "user",
+	Fields: []Field{
+		{Name: "age", Type: TypeInteger, Ops: []string{"gte", "lt"}, Sortable: true},
+		{Name: "userName", Description: "用户名", Example: "张三"},
+	},
+	Model: openAPIUser{},
+	Relations: []Relation{
+		{Name: "dept", Table: "dept", LocalKey: "dept_id", Fields: []Field{{Name: "name", Ops: []string{"eq"}}}},
+	},
+}
+
+func findParameter(params []*OpenAPIParameter, name string) *OpenAPIParameter {
+	for _, p := range params {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func TestOpenAPIParameters(t *testing.T) {
+	params := openAPISchema.OpenAPIParameters()
+	var names []string
+	for _, p := range params {
+		if p.In != "query" {
+			t.Errorf("%s: in = %q", p.Name, p.In)
+		}
+		names = append(names, p.Name)
+	}
+	want := "current,rowCount,orderStr,filter,preset,age,userName,dept.name"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("parameters = %s, want %s", got, want)
+	}
+	if order := findParameter(params, "orderStr"); order.Schema.Pattern != "^((age)(:pd:|:pa:))+$" {
+		t.Errorf("orderStr pattern = %q", order.Schema.Pattern)
+	}
+	if filter := findParameter(params, "filter"); filter.Example != "age=ge=18" {
+		t.Errorf("filter example = %v", filter.Example)
+	}
+}
+
+func TestOpenAPIFieldOperators(t *testing.T) {
+	age := findParameter(openAPISchema.OpenAPIParameters(), "age")
+	branches := age.Schema.AnyOf
+	if len(branches) != 3 {
+		t.Fatalf("anyOf has %d branches, want 3", len(branches))
+	}
+	// 第一个分支是不带操作符的值
+	if branches[0].Type != TypeInteger || branches[0].Title != "" {
+		t.Errorf("plain branch = %+v", branches[0])
+	}
+	for i, op := range []string{"gte", "lt"} {
+		b := branches[i+1]
+		if b.Title != op || b.Type != TypeString {
+			t.Errorf("branch %d = %+v, want title %s", i+1, b, op)
+		}
+		re := regexp.MustCompile(b.Pattern)
+		if !re.MatchString(op+":18") || re.MatchString("18") || re.MatchString(op+":") {
+			t.Errorf("pattern %q does not constrain the %s prefix", b.Pattern, op)
+		}
+		if age.Examples[op] == nil || age.Examples[op].Value != op+":18" {
+			t.Errorf("example for %s = %+v", op, age.Examples[op])
+		}
+	}
+	// lt 的分支不能匹配 lte 前缀的值
+	if regexp.MustCompile(branches[2].Pattern).MatchString("lte:18") {
+		t.Error("lt branch matches lte:18")
+	}
+	userName := findParameter(openAPISchema.OpenAPIParameters(), "userName")
+	if len(userName.Schema.AnyOf) != len(OperatorNames())+1 {
+		t.Errorf("field without Ops has %d branches, want every operator", len(userName.Schema.AnyOf))
+	}
+	if !strings.HasPrefix(userName.Description, "用户名；") {
+		t.Errorf("description = %q", userName.Description)
+	}
+}
+
+func TestOpenAPIODataParameters(t *testing.T) {
+	params := openAPISchema.OpenAPIODataParameters()
+	for _, name := range []string{"$filter", "$orderby", "$top", "$skip", "$count"} {
+		if findParameter(params, name) == nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if filter := findParameter(params, "$filter"); filter.Example != "age ge 18" {
+		t.Errorf("$filter example = %v", filter.Example)
+	}
+	orderBy := regexp.MustCompile(findParameter(params, "$orderby").Schema.Pattern)
+	for value, ok := range map[string]bool{"age": true, "age desc": true, "age asc,age": true, "name": false, "age up": false} {
+		if orderBy.MatchString(value) != ok {
+			t.Errorf("$orderby pattern matches %q = %v", value, !ok)
+		}
+	}
+	if count := findParameter(params, "$count"); len(count.Schema.Enum) != 2 {
+		t.Errorf("$count enum = %v", count.Schema.Enum)
+	}
+	if findParameter((*Schema)(nil).OpenAPIODataParameters(), "$orderby") != nil {
+		t.Error("nil schema documents $orderby")
+	}
+}
+
+func TestOpenAPIResponse(t *testing.T) {
+	resp := openAPISchema.OpenAPIResponse()
+	content := resp.Properties["content"]
+	if content == nil {
+		t.Fatalf("response properties = %v", resp.Properties)
+	}
+	rows := content.Properties["rows"]
+	if rows == nil || rows.Type != "array" || rows.Items.Properties["id"].Format != "int64" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if content.Properties["total"] == nil {
+		t.Fatal("PageBean total missing")
+	}
+}
+
+func TestOpenAPIJSON(t *testing.T) {
+	data, err := json.Marshal(openAPISchema.OpenAPIParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只使用标准的 OpenAPI 属性
+	if strings.Contains(string(data), "x-") {
+		t.Fatalf("document contains extension properties: %s", data)
+	}
+	if !strings.Contains(string(data), `"anyOf":[`) || !strings.Contains(string(data), `"title":"gte"`) {
+		t.Fatalf("operators missing from document: %s", data)
+	}
+}
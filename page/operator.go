@@ -0,0 +1,214 @@
+/**
+ * @Time: 2026/10/19 17:39
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Dialect 数据库方言，自定义操作符可以按方言分别渲染
+type Dialect string
+
+const (
+	MySQL     Dialect = "mysql"
+	Postgres  Dialect = "postgres"
+	SQLite    Dialect = "sqlite"
+	SQLServer Dialect = "sqlserver"
+)
+
+// DefaultDialect Where 和 Cond.SQL 使用的方言
+var DefaultDialect = MySQL
+
+// RenderFunc 将自定义操作符的条件渲染为带 ? 占位符的 SQL 片段和参数
+type RenderFunc func(c *Cond) (string, []interface{})
+
+// Operator 分页查询操作符，url 参数值的前缀为 名称加冒号，例如 geo:
+//
+//	page.RegisterOperator(&page.Operator{
+//		Name: "arr",
+//		Parse: func(column, value string) (*page.Cond, error) {
+//			return &page.Cond{Column: column, Op: "arr", Value: value}, nil
+//		},
+//		Render: map[page.Dialect]page.RenderFunc{
+//			page.Postgres: func(c *page.Cond) (string, []interface{}) {
+//				return c.Column + " @> ARRAY[?]", []interface{}{c.Value}
+//			},
+//		},
+//	})
+type Operator struct {
+
+	/** 名称，只能是小写字母 */
+	Name string
+
+	/** 是否为 or 条件 */
+	Or bool
+
+	/** 说明，用于生成接口文档 */
+	Description string
+
+	/** 解析参数值，column 已转换为下划线列名，返回 nil 表示忽略该参数；Schema 会拒绝 Raw 条件，需要白名单校验时应返回带 Column 和 Op 的条件 */
+	Parse func(column, value string) (*Cond, error)
+
+	/** 按方言渲染，key 为空串时作为默认渲染；Parse 返回标准比较符时可以不设置 */
+	Render map[Dialect]RenderFunc
+
+	/** 内置操作符对应的标准比较符，解析时直接生成条件 key，不经过 Parse */
+	sqlOp string
+}
+
+var (
+	operatorMu sync.RWMutex
+	operators  = make(map[string]*Operator)
+
+	/** 操作符前缀树，注册时整体重建，解析参数时无锁读取 */
+	operatorIndex atomic.Value
+)
+
+// operatorTrie 操作符名称前缀树，名称只有小写字母，每个节点 26 个分支
+type operatorTrie struct {
+	children [26]*operatorTrie
+	op       *Operator
+}
+
+func init() {
+	builtin := []struct {
+		name, op, desc string
+		or             bool
+	}{
+		{"eq", "=", "等于", false},
+		{"lt", "<", "小于", false},
+		{"lte", "<=", "小于等于", false},
+		{"gt", ">", "大于", false},
+		{"gte", ">=", "大于等于", false},
+		{"lk", "LIKE", "前缀模糊匹配", false},
+		{"oreq", "=", "或 等于", true},
+		{"orlt", "<", "或 小于", true},
+		{"orlte", "<=", "或 小于等于", true},
+		{"orgt", ">", "或 大于", true},
+		{"orgte", ">=", "或 大于等于", true},
+		{"orlk", "LIKE", "或 前缀模糊匹配", true},
+	}
+	for _, b := range builtin {
+		op := b.op
+		parse := func(column, value string) (*Cond, error) {
+			return Compare(column, op, value), nil
+		}
+		if op == "LIKE" {
+			parse = func(column, value string) (*Cond, error) {
+				return Compare(column, op, value+"%"), nil
+			}
+		}
+		operators[b.name] = &Operator{Name: b.name, Or: b.or, Description: b.desc, Parse: parse, sqlOp: op}
+	}
+	rebuildOperatorIndex()
+}
+
+// RegisterOperator 注册自定义操作符，建议在路由初始化时注册，名称不能与已有操作符重复
+func RegisterOperator(op *Operator) error {
+	if op == nil || op.Parse == nil {
+		return errors.New("操作符的 Parse 不能为空")
+	}
+	if op.Name == "" {
+		return errors.New("操作符名称不能为空")
+	}
+	for i := 0; i < len(op.Name); i++ {
+		if op.Name[i] < 'a' || op.Name[i] > 'z' {
+			return errors.New("操作符名称只能是小写字母：" + op.Name)
+		}
+	}
+	operatorMu.Lock()
+	defer operatorMu.Unlock()
+	if operators[op.Name] != nil {
+		return errors.New(op.Name + "已注册,无法重复注册")
+	}
+	operators[op.Name] = op
+	rebuildOperatorIndex()
+	return nil
+}
+
+// rebuildOperatorIndex 重建前缀树，调用方需持有写锁
+func rebuildOperatorIndex() {
+	root := &operatorTrie{}
+	for name, op := range operators {
+		node := root
+		for i := 0; i < len(name); i++ {
+			c := name[i] - 'a'
+			if node.children[c] == nil {
+				node.children[c] = &operatorTrie{}
+			}
+			node = node.children[c]
+		}
+		node.op = op
+	}
+	operatorIndex.Store(root)
+}
+
+// matchOperator 按参数值的前缀匹配操作符，例如 gte:10 返回 gte 和 10
+// 只扫描到第一个冒号为止，没有匹配的操作符时返回 nil 和原值
+func matchOperator(value string) (*Operator, string) {
+	node := operatorIndex.Load().(*operatorTrie)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == ':' {
+			if node.op != nil {
+				return node.op, value[i+1:]
+			}
+			return nil, value
+		}
+		if c < 'a' || c > 'z' {
+			return nil, value
+		}
+		if node = node.children[c-'a']; node == nil {
+			return nil, value
+		}
+	}
+	return nil, value
+}
+
+// LookupOperator 按名称查找操作符，找不到返回 nil
+func LookupOperator(name string) *Operator {
+	operatorMu.RLock()
+	defer operatorMu.RUnlock()
+	return operators[name]
+}
+
+// OperatorNames 已注册的全部操作符名称，按名称排序
+func OperatorNames() []string {
+	operatorMu.RLock()
+	defer operatorMu.RUnlock()
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderCustom 渲染自定义操作符，优先使用方言对应的渲染函数
+func renderCustom(c *Cond, d Dialect) (string, []interface{}, bool) {
+	op := LookupOperator(c.Op)
+	if op == nil || op.Render == nil {
+		return "", nil, false
+	}
+	render := op.Render[d]
+	if render == nil {
+		render = op.Render[""]
+	}
+	if render == nil {
+		return "", nil, false
+	}
+	sql, args := render(c)
+	return sql, args, true
+}
+
+// isSQLOp 是否为标准 SQL 比较符
+func isSQLOp(op string) bool {
+	_, ok := sqlOpNames[op]
+	return ok
+}
@@ -0,0 +1,129 @@
+/**
+ * @Time: 2026/10/19 18:50
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// 测试用的自定义操作符，注册后无法移除，名称不能与其它测试重复
+	_ = RegisterOperator(&Operator{
+		Name: "tarr",
+		Parse: func(column, value string) (*Cond, error) {
+			return &Cond{Column: column, Op: "tarr", Value: value}, nil
+		},
+		Render: map[Dialect]RenderFunc{
+			Postgres: func(c *Cond) (string, []interface{}) {
+				return c.Column + " @> ARRAY[?]", []interface{}{c.Value}
+			},
+			"": func(c *Cond) (string, []interface{}) {
+				return "JSON_CONTAINS(" + c.Column + ", ?)", []interface{}{c.Value}
+			},
+		},
+	})
+	_ = RegisterOperator(&Operator{
+		Name: "traw",
+		Parse: func(column, value string) (*Cond, error) {
+			return &Cond{Raw: "JSON_CONTAINS(" + column + ", ?)", Value: value}, nil
+		},
+	})
+}
+
+func TestRegisterOperator(t *testing.T) {
+	tests := []*Operator{
+		nil,
+		{Name: "x"},
+		{Parse: func(string, string) (*Cond, error) { return nil, nil }},
+		{Name: "Geo", Parse: func(string, string) (*Cond, error) { return nil, nil }},
+		{Name: "eq", Parse: func(string, string) (*Cond, error) { return nil, nil }},
+		{Name: "tarr", Parse: func(string, string) (*Cond, error) { return nil, nil }},
+	}
+	for _, op := range tests {
+		if err := RegisterOperator(op); err == nil {
+			t.Errorf("%+v: registered", op)
+		}
+	}
+	names := OperatorNames()
+	if !reflect.DeepEqual(names[:2], []string{"eq", "gt"}) || LookupOperator("tarr") == nil {
+		t.Errorf("OperatorNames = %v", names)
+	}
+}
+
+func TestMatchOperator(t *testing.T) {
+	tests := []struct {
+		value, name, rest string
+	}{
+		{"gte:10", "gte", "10"},
+		{"gt:10", "gt", "10"},
+		{"lte:", "lte", ""},
+		{"oreq:a:b", "oreq", "a:b"},
+		{"tarr:vip", "tarr", "vip"},
+		// 不是已注册的操作符时整个值按原样返回
+		{"gtx:10", "", "gtx:10"},
+		{"GTE:10", "", "GTE:10"},
+		{"12:30", "", "12:30"},
+		{"gte", "", "gte"},
+	}
+	for _, tt := range tests {
+		op, rest := matchOperator(tt.value)
+		name := ""
+		if op != nil {
+			name = op.Name
+		}
+		if name != tt.name || rest != tt.rest {
+			t.Errorf("matchOperator(%q) = %q %q, want %q %q", tt.value, name, rest, tt.name, tt.rest)
+		}
+	}
+}
+
+func TestCustomOperatorRender(t *testing.T) {
+	for _, query := range []string{"userTags=tarr:vip&status=1", "filter=userTags=tarr=vip;status==1"} {
+		p, err := ParsePageParam(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		where, args := p.Where()
+		if !strings.Contains(where, "JSON_CONTAINS(user_tags, ?)") || !strings.Contains(where, "status = ?") || len(args) != 2 {
+			t.Errorf("%s: Where = %s %v", query, where, args)
+		}
+		if where, _ = p.WhereDialect(Postgres); !strings.Contains(where, "user_tags @> ARRAY[?]") {
+			t.Errorf("%s: postgres Where = %s", query, where)
+		}
+	}
+	// 自定义操作符受 Field.Ops 约束
+	s := &Schema{Fields: []Field{{Name: "userTags", Ops: []string{"tarr"}}, {Name: "name", Ops: []string{"eq"}}}}
+	p, _ := parsePageParam("userTags=tarr:vip")
+	if err := s.Apply(p); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = parsePageParam("name=tarr:vip")
+	if err := s.Apply(p); err == nil {
+		t.Error("operator outside Field.Ops accepted")
+	}
+}
+
+func TestSchemaRejectsRaw(t *testing.T) {
+	s := &Schema{Fields: []Field{{Name: "tags"}}}
+	p, err := parsePageParam("tags=traw:vip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Apply(p); err == nil || !strings.Contains(err.Error(), "无法校验") {
+		t.Errorf("raw param: err = %v", err)
+	}
+	for _, p := range []*PageInfo{
+		{Filter: &Cond{Raw: "1 = 1 OR tags = ?", Value: 1}},
+		{Filter: And(Compare("tags", "=", 1), &Cond{Raw: "1 = 1"})},
+		{OrFilter: Not(&Cond{Raw: "tags = ?", Value: 1})},
+	} {
+		if err = s.Apply(p); err == nil || !strings.Contains(err.Error(), "无法校验") {
+			t.Errorf("raw cond: err = %v", err)
+		}
+	}
+}
@@ -6,57 +6,17 @@
 package page
 
 import (
-	"bytes"
+	"errors"
 	"log"
-	"net/url"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/naming"
 )
 
 const (
 
-	/** ------- and 条件 ------  */
-	/** 小于 < */
-	lt = "lt:"
-
-	/** 大于 > */
-	gt = "gt:"
-
-	/** 小于 <= */
-	lte = "lte:"
-
-	/** 大于 >= */
-	gte = "gte:"
-
-	/** 默认是等于 */
-	eq = "eq:"
-
-	/** 模糊查询 */
-	lk = "lk:"
-
-	/** ------- or 条件 ------  */
-
-	/** 小于 */
-	orlt = "orlt:"
-
-	/** 大于  */
-	orgt = "orgt:"
-
-	/** 小于 */
-	orlte = "orlte:"
-
-	/** 大于  */
-	orgte = "orgte:"
-
-	/** 默认是等于 */
-	oreq = "oreq:"
-
-	/** 模糊查询 */
-	orlk = "orlk:"
-
 	/** ------- 排序 ------  */
 
 	/** 降序 */
@@ -90,6 +50,9 @@ type PageInfo struct {
 	/** 每页显示的最大行数 */
 	RowCount         int
 
+	/** 跳过的行数，来自 OData 的 $skip，大于 0 时优先于 Current，见 Offset */
+	Skip             int
+
 	/** 表名 仅限于指定表名去查询 */
 	TableName        string
 
@@ -101,27 +64,107 @@ type PageInfo struct {
 
 	/** 排序 */
 	OrderStr         string
+
+	/** 分组或复杂的查询条件，与 AndParams 以 AND 连接，来自 filter 参数 */
+	Filter           *Cond
+
+	/** 以 OR 连接的复杂条件，来自自定义的 or 操作符 */
+	OrFilter         *Cond
+
+	/** 服务端强制附加的范围条件，如租户、数据权限，不接受客户端传入 */
+	Scope            *Cond
+}
+
+// Clone 深拷贝分页参数，条件 map 和条件树各自独立，修改副本不会影响原对象
+func (p *PageInfo) Clone() *PageInfo {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.AndParams = make(map[string]interface{}, len(p.AndParams))
+	for k, v := range p.AndParams {
+		c.AndParams[k] = v
+	}
+	c.OrParams = make(map[string]interface{}, len(p.OrParams))
+	for k, v := range p.OrParams {
+		c.OrParams[k] = v
+	}
+	c.Filter = p.Filter.Clone()
+	c.OrFilter = p.OrFilter.Clone()
+	c.Scope = p.Scope.Clone()
+	return &c
+}
+
+// Offset 查询跳过的行数，设置了 Skip 时使用 Skip，否则按 Current 和 RowCount 计算
+// OData 客户端的 $skip 可以不是 $top 的整数倍，此时 Current 只是近似值，查询需要使用 Offset
+func (p *PageInfo) Offset() int {
+	if p.Skip > 0 {
+		return p.Skip
+	}
+	if p.Current < 1 {
+		return 0
+	}
+	return (p.Current - 1) * p.RowCount
 }
 
-// PageParam 获取url查询参数
+// PageParam 获取url查询参数，并附加已注册的范围条件
 func PageParam(c *gin.Context) *PageInfo {
-	s := c.Request.URL.RawQuery
-	paramStr, err := url.QueryUnescape(s)
+	pageInfo, err := ParsePageParam(c.Request.URL.RawQuery)
 	if err != nil {
-		log.Println("url参数decode异常：" + err.Error())
+		log.Println("url参数解析异常：" + err.Error())
+		return nil
+	}
+	if err = ApplyScopes(c, pageInfo); err != nil {
+		log.Println(err.Error())
 		return nil
 	}
+	return pageInfo
+}
+
+// ParsePageParam 解析url查询参数，filter 参数为 RSQL/FIQL 表达式，见 ParseRSQL
+// preset 参数引用已保存的预设，url 中的其余参数与预设合并，见 Preset.Merge
+// 带有 $filter $top 等参数时按 OData 约定解析，见 ParseOData
+// 没有请求上下文，不会附加范围条件，需要时调用 ApplyScopes
+// 查询串只扫描一遍，参数逐个解码，值中编码过的 & 和 = 不会被当作分隔符
+// 不需要解码的参数直接使用原串的子串，结果中的 map、条件值和转换后的列名仍需分配内存
+// 点号分隔的关联路径只能通过 Schema 白名单查询，这里出现时返回错误
+func ParsePageParam(rawQuery string) (*PageInfo, error) {
+	pageInfo, err := parsePageParam(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRelationPaths(pageInfo); err != nil {
+		return nil, err
+	}
+	return pageInfo, nil
+}
+
+// parsePageParam 解析url查询参数，保留关联路径，由 Schema.Apply 校验和转换
+func parsePageParam(rawQuery string) (*PageInfo, error) {
 	pageInfo := PageInfo{}
 	andParams := make(map[string]interface{})
 	orParams := make(map[string]interface{})
-	paramArr := strings.Split(paramStr, "&")
-	for _, v := range paramArr {
-		ky := strings.Split(v, "=")
-		if len(ky) != 2 {
-			continue
+	filter := ""
+	preset := ""
+	scanner := queryScanner{rest: rawQuery}
+	for rawKey, rawValue, ok := scanner.next(); ok; rawKey, rawValue, ok = scanner.next() {
+		if isODataKey(rawKey) {
+			return parseOData(rawQuery)
+		}
+		key, err := unescape(rawKey)
+		if err != nil {
+			return nil, err
 		}
-		if ky[0] == "current" {
-			current, err := strconv.Atoi(ky[1])
+		value, err := unescape(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "filter":
+			filter = value
+			continue
+		case "current":
+			current, err := strconv.Atoi(value)
 			if err != nil {
 				current = 1
 			}
@@ -130,8 +173,8 @@ func PageParam(c *gin.Context) *PageInfo {
 			}
 			pageInfo.Current = current
 			continue
-		} else if ky[0] == "rowCount" {
-			rowCount, err := strconv.Atoi(ky[1])
+		case "rowCount":
+			rowCount, err := strconv.Atoi(value)
 			if err != nil {
 				rowCount = 10
 			}
@@ -142,179 +185,110 @@ func PageParam(c *gin.Context) *PageInfo {
 			}
 			pageInfo.RowCount = rowCount
 			continue
-		} else if ky[0] == "orderStr" {
-			pageInfo.OrderStr = ky[1]
+		case "orderStr":
+			pageInfo.OrderStr = value
 			continue
-		} else if ky[0] == "tableName" {
-			pageInfo.TableName = ky[1]
+		case "tableName":
+			pageInfo.TableName = value
 			continue
-		}
-		key := ky[0]
-		value := ky[1]
-		if key == "_t" || key == "_time" || key == "_timestamp" {
+		case "preset":
+			preset = value
 			continue
-		} else if strings.Index(value, lt) == 0 {
-			value = strings.Replace(value, lt, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			andParams[key+" < ?"] = value
+		case "_t", "_time", "_timestamp":
 			continue
-		} else if strings.Index(value, lte) == 0 {
-			value = strings.Replace(value, lte, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			andParams[key+" <= ?"] = value
+		}
+		// 值的前缀为已注册的操作符时按操作符解析，否则整个值按等于处理
+		op, value := matchOperator(value)
+		if op == nil {
+			op = LookupOperator("eq")
+		}
+		if value == "" {
 			continue
-		} else if strings.Index(value, gt) == 0 {
-			value = strings.Replace(value, gt, "", 1)
-			if value == "" {
-				continue
+		}
+		column := CamelToCase(key)
+		if op.sqlOp != "" {
+			params := andParams
+			if op.Or {
+				params = orParams
 			}
-			key = CamelToCase(key)
-			andParams[key+" > ?"] = value
-			continue
-		} else if strings.Index(value, gte) == 0 {
-			value = strings.Replace(value, gte, "", 1)
-			if value == "" {
-				continue
+			if op.sqlOp == "LIKE" {
+				value += "%"
 			}
-			key = CamelToCase(key)
-			andParams[key+" >= ?"] = value
+			params[column+" "+op.sqlOp+" ?"] = value
 			continue
-		} else if strings.Index(value, lk) == 0 {
-			value = strings.Replace(value, lk, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			andParams[key+" LIKE ?"] = value + "%"
-			continue
-		} else if strings.Index(value, eq) == 0 {
-			value = strings.Replace(value, eq, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			andParams[key+" = ?"] = value
+		}
+		cond, err := op.Parse(column, value)
+		if err != nil {
+			return nil, errors.New("参数" + key + "解析异常：" + err.Error())
+		}
+		if cond == nil {
 			continue
-		} else if strings.Index(value, orlt) == 0 {
-			value = strings.Replace(value, orlt, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			orParams[key+" < ?"] = value
-		} else if strings.Index(value, orlte) == 0 {
-			value = strings.Replace(value, orlte, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			orParams[key+" <= ?"] = value
-		}  else if strings.Index(value, orgte) == 0 {
-			value = strings.Replace(value, orgte, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			orParams[key+" >= ?"] = value
-		}else if strings.Index(value, orgt) == 0 {
-			value = strings.Replace(value, orgt, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			orParams[key+" > ?"] = value
-		} else if strings.Index(value, orlk) == 0 {
-			value = strings.Replace(value, oreq, "", 1)
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			orParams[key+" LIKE ?"] = value + "%"
-		} else if strings.Index(value, oreq) == 0 {
-			value = strings.Replace(value, oreq, "", 1)
-			if value == "" {
-				continue
+		}
+		if cond.IsLeaf() && !cond.Not && (cond.Raw != "" || isSQLOp(cond.Op)) {
+			if op.Or {
+				orParams[cond.Key()] = cond.Value
+			} else {
+				andParams[cond.Key()] = cond.Value
 			}
-			key = CamelToCase(key)
-			orParams[key+" = ?"] = value
+		} else if op.Or {
+			pageInfo.OrFilter = Or(pageInfo.OrFilter, cond)
 		} else {
-			if value == "" {
-				continue
-			}
-			key = CamelToCase(key)
-			andParams[key+" = ?"] = value
+			pageInfo.Filter = And(pageInfo.Filter, cond)
 		}
 	}
 	if pageInfo.OrderStr != "" {
-		v := CamelToCase(pageInfo.OrderStr)
-		v = strings.ReplaceAll(v, pd, " desc,")
-		v = strings.ReplaceAll(v, pa, " asc,")
-		v = strings.TrimSuffix(v, ",")
-		pageInfo.OrderStr = v
+		pageInfo.OrderStr = parseOrderStr(pageInfo.OrderStr)
 	}
 	pageInfo.AndParams = andParams
 	pageInfo.OrParams = orParams
-	return &pageInfo
-}
-
-func CamelToCase(name string) string {
-	buffer := NewBuffer()
-	for i, r := range name {
-		if unicode.IsUpper(r) {
-			if i != 0 {
-				buffer.Append('_')
-			}
-			buffer.Append(unicode.ToLower(r))
-		} else {
-			buffer.Append(r)
+	if filter != "" {
+		cond, err := ParseRSQL(filter)
+		if err != nil {
+			return nil, err
 		}
+		pageInfo.AddFilter(cond)
 	}
-	return buffer.String()
-}
-
-// Buffer 内嵌bytes.Buffer，支持连写
-type Buffer struct {
-	*bytes.Buffer
-}
-
-func NewBuffer() *Buffer {
-	return &Buffer{Buffer: new(bytes.Buffer)}
+	if preset != "" {
+		ps, err := loadPreset(preset)
+		if err != nil {
+			return nil, errors.New("预设" + preset + "读取失败：" + err.Error())
+		}
+		return ps.Merge(&pageInfo), nil
+	}
+	return &pageInfo, nil
 }
 
-func (b *Buffer) Append(i interface{}) *Buffer {
-	switch val := i.(type) {
-	case string:
-		b.append(val)
-	case int:
-		b.append(strconv.Itoa(val))
-	case int64:
-		b.append(strconv.FormatInt(val, 10))
-	case uint:
-		b.append(strconv.FormatUint(uint64(val), 10))
-	case uint64:
-		b.append(strconv.FormatUint(val, 10))
-	case []byte:
-		_, _ = b.Write(val)
-	case rune:
-		_, _ = b.WriteRune(val)
+// CamelToCase 驼峰转下划线，例如 userID 转为 user_id，点号分隔的关联路径逐段转换
+// 字母数字以外的字符会被当作分隔符，转换结果可以安全地拼接到 SQL 中
+func CamelToCase(name string) string {
+	if strings.IndexByte(name, '.') < 0 {
+		return naming.ToSnake(name)
 	}
-	return b
+	parts := strings.Split(name, ".")
+	for i := range parts {
+		parts[i] = naming.ToSnake(parts[i])
+	}
+	return strings.Join(parts, ".")
 }
 
-func (b *Buffer) append(s string) *Buffer {
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("*****内存不够了！******")
+// parseOrderStr 将 createTime:pd:id:pa: 转换为 create_time desc,id asc
+func parseOrderStr(orderStr string) string {
+	var orders []string
+	for orderStr != "" {
+		field, direction := orderStr, ""
+		i, j := strings.Index(orderStr, pd), strings.Index(orderStr, pa)
+		if i >= 0 && (j < 0 || i < j) {
+			field, direction, orderStr = orderStr[:i], " desc", orderStr[i+len(pd):]
+		} else if j >= 0 {
+			field, direction, orderStr = orderStr[:j], " asc", orderStr[j+len(pa):]
+		} else {
+			orderStr = ""
 		}
-	}()
-	_, _ = b.WriteString(s)
-	return b
+		if field = CamelToCase(field); field != "" {
+			orders = append(orders, field+direction)
+		}
+	}
+	return strings.Join(orders, ",")
 }
 
 // CheckPageRows 获取页数和行数
@@ -0,0 +1,410 @@
+/**
+ * @Time: 2026/10/19 17:48
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// 典型的列表查询：分页、排序、时间戳以及多个条件
+const benchQuery = "current=3&rowCount=20&orderStr=createTime:pd:id:pa:&_t=1700000000000" +
+	"&userName=lk:%E5%BC%A0&status=1&createTime=gte:2022-01-01&createTime=lt:2022-02-01" +
+	"&deptId=oreq:12&deptId=oreq:13&amount=lte:100"
+
+func BenchmarkParsePageParam(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParsePageParam(benchQuery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParsePageParamBaseline(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if baselinePageParam(benchQuery) == nil {
+			b.Fatal("baseline parse failed")
+		}
+	}
+}
+
+func BenchmarkIsOData(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsOData(benchQuery)
+	}
+}
+
+func BenchmarkIsODataLegacy(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		legacyIsOData(benchQuery)
+	}
+}
+
+// 结果本身需要分配内存，这里只保证中间字符串的分配比基线实现少
+func TestParsePageParamAllocs(t *testing.T) {
+	got := testing.AllocsPerRun(100, func() {
+		_, _ = ParsePageParam(benchQuery)
+	})
+	baseline := testing.AllocsPerRun(100, func() {
+		baselinePageParam(benchQuery)
+	})
+	if got >= baseline {
+		t.Fatalf("ParsePageParam allocates %v times per run, baseline %v", got, baseline)
+	}
+}
+
+// 与基线实现的解析结果一致
+func TestParsePageParamMatchesBaseline(t *testing.T) {
+	got, err := ParsePageParam(benchQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := baselinePageParam(benchQuery)
+	if got.Current != want.Current || got.RowCount != want.RowCount || got.OrderStr != want.OrderStr {
+		t.Fatalf("page = %d/%d/%q, want %d/%d/%q", got.Current, got.RowCount, got.OrderStr, want.Current, want.RowCount, want.OrderStr)
+	}
+	if !reflect.DeepEqual(got.AndParams, want.AndParams) {
+		t.Fatalf("AndParams = %v, want %v", got.AndParams, want.AndParams)
+	}
+	if !reflect.DeepEqual(got.OrParams, want.OrParams) {
+		t.Fatalf("OrParams = %v, want %v", got.OrParams, want.OrParams)
+	}
+}
+
+func TestQueryScanner(t *testing.T) {
+	s := queryScanner{rest: "a=1&&b=&c&d=x=y&=e&f=2"}
+	var got []string
+	for key, value, ok := s.next(); ok; key, value, ok = s.next() {
+		got = append(got, key+"|"+value)
+	}
+	// 没有 = 或 key 为空的参数跳过，value 只按第一个 = 切分
+	want := []string{"a|1", "b|", "d|x=y", "f|2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scan = %q, want %q", got, want)
+	}
+}
+
+func TestParsePageParamEncoded(t *testing.T) {
+	tests := []struct {
+		query string
+		key   string
+		want  interface{}
+	}{
+		// 编码过的 & 和 = 属于值本身
+		{"name=a%26b%3Dc&status=1", "name = ?", "a&b=c"},
+		{"name=a+b", "name = ?", "a b"},
+		{"userName=lk:%E5%BC%A0", "user_name LIKE ?", "张%"},
+		// 操作符前缀本身被编码
+		{"age=gte%3A18", "age >= ?", "18"},
+		{"user%4Eame=x", "user_name = ?", "x"},
+	}
+	for _, tt := range tests {
+		info, err := ParsePageParam(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got := info.AndParams[tt.key]; got != tt.want {
+			t.Errorf("%s: AndParams[%q] = %v, want %v (all: %v)", tt.query, tt.key, got, tt.want, info.AndParams)
+		}
+	}
+}
+
+func TestParsePageParamEmptyValues(t *testing.T) {
+	info, err := ParsePageParam("status=&name=eq:&age=gte:&flag&current=&rowCount=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.AndParams) != 0 || len(info.OrParams) != 0 {
+		t.Fatalf("empty values produced conditions: %v %v", info.AndParams, info.OrParams)
+	}
+	if info.Current != 1 || info.RowCount != 10 {
+		t.Fatalf("page = %d/%d, want 1/10", info.Current, info.RowCount)
+	}
+}
+
+func TestParsePageParamInvalidEscape(t *testing.T) {
+	if _, err := ParsePageParam("name=%zz"); err == nil {
+		t.Fatal("invalid escape accepted")
+	}
+}
+
+func TestParsePageParamOData(t *testing.T) {
+	for _, query := range []string{"$top=5&$skip=10", "%24top=5&%24skip=10", "status=1&%24top=5&%24skip=10"} {
+		if !IsOData(query) {
+			t.Errorf("IsOData(%q) = false", query)
+		}
+		info, err := ParsePageParam(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if info.Current != 3 || info.RowCount != 5 {
+			t.Errorf("%s: page = %d/%d, want 3/5", query, info.Current, info.RowCount)
+		}
+	}
+	for _, query := range []string{"top=5", "a=%24top", "%2524top=5", "$topx=5"} {
+		if IsOData(query) {
+			t.Errorf("IsOData(%q) = true", query)
+		}
+	}
+}
+
+func TestCamelToCase(t *testing.T) {
+	tests := map[string]string{
+		"userName":          "user_name",
+		"_id":               "_id",
+		"userID":            "user_id",
+		"customer.cityName": "customer.city_name",
+		"名字ID":              "名字_id",
+		"a;drop table":      "a_drop_table",
+	}
+	for in, want := range tests {
+		if got := CamelToCase(in); got != want {
+			t.Errorf("CamelToCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+	info, err := ParsePageParam("_id=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.AndParams["_id = ?"] != "3" {
+		t.Errorf("AndParams = %v", info.AndParams)
+	}
+}
+
+func TestPageInfoClone(t *testing.T) {
+	p := &PageInfo{
+		AndParams: map[string]interface{}{"status = ?": 1},
+		OrParams:  map[string]interface{}{},
+		Filter:    And(Compare("age", ">=", 18), Or(Compare("vip", "=", 1), Compare("level", ">", 3))),
+		OrFilter:  Compare("dept_id", "=", 12),
+		Scope:     Compare("tenant_id", "=", 7),
+	}
+	c := p.Clone()
+	c.AndParams["status = ?"] = 2
+	c.Filter.Children[1].Children[0].Value = 2
+	c.Filter.Children = append(c.Filter.Children[:1], Compare("x", "=", 1))
+	c.OrFilter.Column = "x"
+	c.Scope.Value = 8
+	if p.AndParams["status = ?"] != 1 {
+		t.Fatal("Clone shares AndParams")
+	}
+	if where, args := p.Where(); where != "tenant_id = ? AND ((status = ? AND (age >= ? AND (vip = ? OR level > ?))) OR dept_id = ?)" ||
+		!reflect.DeepEqual(args, []interface{}{7, 1, 18, 1, 3, 12}) {
+		t.Fatalf("original changed: %s %v", where, args)
+	}
+}
+
+// legacyIsOData 改为单遍扫描之前的实现，仅用于基准对比
+func legacyIsOData(rawQuery string) bool {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{"$filter", "$orderby", "$top", "$skip", "$count"} {
+		if _, ok := values[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// baselinePageParam 基线版本 PageParam 的解析逻辑，仅把参数从 gin.Context 改为查询串，用于基准对比
+// 其中 orlk 使用 oreq 前缀替换是基线本身的行为
+func baselinePageParam(s string) *PageInfo {
+	const (
+		lt    = "lt:"
+		gt    = "gt:"
+		lte   = "lte:"
+		gte   = "gte:"
+		eq    = "eq:"
+		lk    = "lk:"
+		orlt  = "orlt:"
+		orgt  = "orgt:"
+		orlte = "orlte:"
+		orgte = "orgte:"
+		oreq  = "oreq:"
+		orlk  = "orlk:"
+	)
+	paramStr, err := url.QueryUnescape(s)
+	if err != nil {
+		return nil
+	}
+	pageInfo := PageInfo{}
+	andParams := make(map[string]interface{})
+	orParams := make(map[string]interface{})
+	paramArr := strings.Split(paramStr, "&")
+	for _, v := range paramArr {
+		ky := strings.Split(v, "=")
+		if len(ky) != 2 {
+			continue
+		}
+		if ky[0] == "current" {
+			current, err := strconv.Atoi(ky[1])
+			if err != nil {
+				current = 1
+			}
+			if current < 1 {
+				current = 1
+			}
+			pageInfo.Current = current
+			continue
+		} else if ky[0] == "rowCount" {
+			rowCount, err := strconv.Atoi(ky[1])
+			if err != nil {
+				rowCount = 10
+			}
+			if rowCount < 1 {
+				rowCount = 10
+			} else if rowCount > 100 {
+				rowCount = 100
+			}
+			pageInfo.RowCount = rowCount
+			continue
+		} else if ky[0] == "orderStr" {
+			pageInfo.OrderStr = ky[1]
+			continue
+		} else if ky[0] == "tableName" {
+			pageInfo.TableName = ky[1]
+			continue
+		}
+		key := ky[0]
+		value := ky[1]
+		if key == "_t" || key == "_time" || key == "_timestamp" {
+			continue
+		} else if strings.Index(value, lt) == 0 {
+			value = strings.Replace(value, lt, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" < ?"] = value
+			continue
+		} else if strings.Index(value, lte) == 0 {
+			value = strings.Replace(value, lte, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" <= ?"] = value
+			continue
+		} else if strings.Index(value, gt) == 0 {
+			value = strings.Replace(value, gt, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" > ?"] = value
+			continue
+		} else if strings.Index(value, gte) == 0 {
+			value = strings.Replace(value, gte, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" >= ?"] = value
+			continue
+		} else if strings.Index(value, lk) == 0 {
+			value = strings.Replace(value, lk, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" LIKE ?"] = value + "%"
+			continue
+		} else if strings.Index(value, eq) == 0 {
+			value = strings.Replace(value, eq, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" = ?"] = value
+			continue
+		} else if strings.Index(value, orlt) == 0 {
+			value = strings.Replace(value, orlt, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" < ?"] = value
+		} else if strings.Index(value, orlte) == 0 {
+			value = strings.Replace(value, orlte, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" <= ?"] = value
+		} else if strings.Index(value, orgte) == 0 {
+			value = strings.Replace(value, orgte, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" >= ?"] = value
+		} else if strings.Index(value, orgt) == 0 {
+			value = strings.Replace(value, orgt, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" > ?"] = value
+		} else if strings.Index(value, orlk) == 0 {
+			value = strings.Replace(value, oreq, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" LIKE ?"] = value + "%"
+		} else if strings.Index(value, oreq) == 0 {
+			value = strings.Replace(value, oreq, "", 1)
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			orParams[key+" = ?"] = value
+		} else {
+			if value == "" {
+				continue
+			}
+			key = baselineCamelToCase(key)
+			andParams[key+" = ?"] = value
+		}
+	}
+	if pageInfo.OrderStr != "" {
+		v := baselineCamelToCase(pageInfo.OrderStr)
+		v = strings.ReplaceAll(v, pd, " desc,")
+		v = strings.ReplaceAll(v, pa, " asc,")
+		v = strings.TrimSuffix(v, ",")
+		pageInfo.OrderStr = v
+	}
+	pageInfo.AndParams = andParams
+	pageInfo.OrParams = orParams
+	return &pageInfo
+}
+
+// baselineCamelToCase 基线版本的 CamelToCase，基线的 Buffer.Append 对 rune 按字符写入
+func baselineCamelToCase(name string) string {
+	buffer := new(bytes.Buffer)
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i != 0 {
+				buffer.WriteRune('_')
+			}
+			buffer.WriteRune(unicode.ToLower(r))
+		} else {
+			buffer.WriteRune(r)
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,452 @@
+/**
+ * @Time: 2026/10/19 17:40
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+
+	/** 预设 JSON 格式的当前版本 */
+	PresetVersion = 1
+
+	/** 预设文件的扩展名 */
+	presetExt = ".json"
+)
+
+// ErrPresetNotFound 预设不存在
+var ErrPresetNotFound = errors.New("预设不存在")
+
+// Preset 保存的查询预设，包含条件、排序和每页行数，不包含当前页和服务端范围条件
+type Preset struct {
+
+	/** 格式版本 */
+	Version int `json:"version"`
+
+	/** 预设名称，只能包含字母、数字、下划线和中划线 */
+	Name string `json:"name"`
+
+	/** 每页行数 */
+	RowCount int `json:"rowCount,omitempty"`
+
+	/** 排序 */
+	OrderStr string `json:"orderStr,omitempty"`
+
+	/** and 条件，按 key 排序 */
+	And []PresetParam `json:"and,omitempty"`
+
+	/** or 条件，按 key 排序 */
+	Or []PresetParam `json:"or,omitempty"`
+
+	/** 分组条件 */
+	Filter *Cond `json:"filter,omitempty"`
+
+	/** 以 OR 连接的分组条件 */
+	OrFilter *Cond `json:"orFilter,omitempty"`
+}
+
+// PresetParam AndParams 和 OrParams 中的一个条件
+type PresetParam struct {
+
+	/** 条件，例如 "age >= ?" */
+	Key string `json:"key"`
+
+	/** 参数 */
+	Value interface{} `json:"value"`
+}
+
+// PresetStore 预设存储
+type PresetStore interface {
+
+	// Get 按名称读取预设，不存在时返回 ErrPresetNotFound
+	Get(name string) (*Preset, error)
+
+	// Save 保存预设，同名覆盖
+	Save(preset *Preset) error
+
+	// Delete 删除预设，不存在时不报错
+	Delete(name string) error
+
+	// List 全部预设名称，按名称排序
+	List() ([]string, error)
+}
+
+var (
+	presetMu    sync.RWMutex
+	presetStore PresetStore
+)
+
+// SetPresetStore 设置 preset 参数使用的全局存储
+func SetPresetStore(store PresetStore) {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+	presetStore = store
+}
+
+// loadPreset 从全局存储读取预设
+func loadPreset(name string) (*Preset, error) {
+	presetMu.RLock()
+	store := presetStore
+	presetMu.RUnlock()
+	if store == nil {
+		return nil, errors.New("未设置预设存储，无法使用 preset 参数")
+	}
+	return store.Get(name)
+}
+
+// NewPreset 由分页参数生成预设
+func NewPreset(name string, p *PageInfo) (*Preset, error) {
+	if err := checkPresetName(name); err != nil {
+		return nil, err
+	}
+	preset := &Preset{
+		Version:  PresetVersion,
+		Name:     name,
+		RowCount: p.RowCount,
+		OrderStr: p.OrderStr,
+		And:      presetParams(p.AndParams),
+		Or:       presetParams(p.OrParams),
+		Filter:   p.Filter,
+		OrFilter: p.OrFilter,
+	}
+	if err := preset.check(); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// Marshal 序列化为稳定的 JSON，相同的条件总是得到相同的结果
+func (ps *Preset) Marshal() ([]byte, error) {
+	return json.Marshal(ps)
+}
+
+// UnmarshalPreset 解析预设 JSON，并校验版本、名称和条件的合法性
+func UnmarshalPreset(data []byte) (*Preset, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	preset := &Preset{}
+	if err := decoder.Decode(preset); err != nil {
+		return nil, errors.New("预设格式错误：" + err.Error())
+	}
+	if preset.Version < 1 || preset.Version > PresetVersion {
+		return nil, fmt.Errorf("不支持的预设版本：%d", preset.Version)
+	}
+	if err := checkPresetName(preset.Name); err != nil {
+		return nil, err
+	}
+	for i := range preset.And {
+		preset.And[i].Value = normalizeNumber(preset.And[i].Value)
+	}
+	for i := range preset.Or {
+		preset.Or[i].Value = normalizeNumber(preset.Or[i].Value)
+	}
+	normalizeCond(preset.Filter)
+	normalizeCond(preset.OrFilter)
+	if err := preset.check(); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// PageInfo 还原为分页参数
+func (ps *Preset) PageInfo() *PageInfo {
+	p := &PageInfo{
+		RowCount:  ps.RowCount,
+		OrderStr:  ps.OrderStr,
+		AndParams: make(map[string]interface{}, len(ps.And)),
+		OrParams:  make(map[string]interface{}, len(ps.Or)),
+		Filter:    ps.Filter,
+		OrFilter:  ps.OrFilter,
+	}
+	for _, param := range ps.And {
+		p.AndParams[param.Key] = param.Value
+	}
+	for _, param := range ps.Or {
+		p.OrParams[param.Key] = param.Value
+	}
+	return p
+}
+
+// Merge 以预设为基础合并 url 中的参数
+// url 中相同的条件覆盖预设，其余条件叠加；url 中指定了每页行数或排序时以 url 为准
+func (ps *Preset) Merge(p *PageInfo) *PageInfo {
+	merged := ps.PageInfo()
+	merged.Current = p.Current
+	merged.Skip = p.Skip
+	merged.TableName = p.TableName
+	merged.Scope = p.Scope
+	if p.RowCount > 0 {
+		merged.RowCount = p.RowCount
+	}
+	if p.OrderStr != "" {
+		merged.OrderStr = p.OrderStr
+	}
+	for k, v := range p.AndParams {
+		merged.AndParams[k] = v
+	}
+	for k, v := range p.OrParams {
+		merged.OrParams[k] = v
+	}
+	merged.Filter = And(merged.Filter, p.Filter)
+	merged.OrFilter = Or(merged.OrFilter, p.OrFilter)
+	return merged
+}
+
+// check 预设中只允许标准比较条件和已注册的操作符，不允许原始 SQL 片段
+func (ps *Preset) check() error {
+	for _, params := range [][]PresetParam{ps.And, ps.Or} {
+		for _, param := range params {
+			column, op := splitKey(param.Key)
+			if err := checkPresetCond(&Cond{Column: column, Op: op}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := checkPresetCond(ps.Filter); err != nil {
+		return err
+	}
+	return checkPresetCond(ps.OrFilter)
+}
+
+func checkPresetCond(c *Cond) error {
+	if c == nil {
+		return nil
+	}
+	if c.Raw != "" {
+		return errors.New("预设中不能包含原始 SQL 条件")
+	}
+	if !c.IsLeaf() {
+		if c.Logic != logicAnd && c.Logic != logicOr {
+			return errors.New("预设中的条件连接词不合法：" + c.Logic)
+		}
+		for _, child := range c.Children {
+			if err := checkPresetCond(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if c.Column == "" {
+		return errors.New("预设中的条件缺少列名")
+	}
+	for i := 0; i < len(c.Column); i++ {
+		if !isSelectorChar(c.Column[i]) {
+			return errors.New("预设中的列名不合法：" + c.Column)
+		}
+	}
+	if !isSQLOp(c.Op) && LookupOperator(c.Op) == nil {
+		return errors.New("预设中的比较符不合法：" + c.Op)
+	}
+	return nil
+}
+
+func checkPresetName(name string) error {
+	if name == "" {
+		return errors.New("预设名称不能为空")
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return errors.New("预设名称只能包含字母、数字、下划线和中划线：" + name)
+		}
+	}
+	return nil
+}
+
+func presetParams(params map[string]interface{}) []PresetParam {
+	keys := sortedKeys(params)
+	if len(keys) == 0 {
+		return nil
+	}
+	list := make([]PresetParam, len(keys))
+	for i, key := range keys {
+		list[i] = PresetParam{Key: key, Value: params[key]}
+	}
+	return list
+}
+
+// normalizeNumber json.Number 还原为 int64 或 float64，切片逐个还原
+func normalizeNumber(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case []interface{}:
+		for i := range val {
+			val[i] = normalizeNumber(val[i])
+		}
+	}
+	return v
+}
+
+func normalizeCond(c *Cond) {
+	if c == nil {
+		return
+	}
+	c.Value = normalizeNumber(c.Value)
+	for _, child := range c.Children {
+		normalizeCond(child)
+	}
+}
+
+// MemoryPresetStore 内存中的预设存储，保存序列化后的副本，读写互不影响
+type MemoryPresetStore struct {
+	mu      sync.RWMutex
+	presets map[string][]byte
+}
+
+// NewMemoryPresetStore 创建内存预设存储
+func NewMemoryPresetStore() *MemoryPresetStore {
+	return &MemoryPresetStore{presets: make(map[string][]byte)}
+}
+
+func (m *MemoryPresetStore) Get(name string) (*Preset, error) {
+	m.mu.RLock()
+	data, ok := m.presets[name]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrPresetNotFound
+	}
+	return UnmarshalPreset(data)
+}
+
+func (m *MemoryPresetStore) Save(preset *Preset) error {
+	if err := checkPresetName(preset.Name); err != nil {
+		return err
+	}
+	data, err := preset.Marshal()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.presets[preset.Name] = data
+	return nil
+}
+
+func (m *MemoryPresetStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.presets, name)
+	return nil
+}
+
+func (m *MemoryPresetStore) List() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.presets))
+	for name := range m.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FilePresetStore 文件预设存储，每个预设保存为目录下的 名称.json
+type FilePresetStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFilePresetStore 创建文件预设存储，目录不存在时自动创建
+func NewFilePresetStore(dir string) (*FilePresetStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FilePresetStore{dir: dir}, nil
+}
+
+func (f *FilePresetStore) path(name string) (string, error) {
+	if err := checkPresetName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(f.dir, name+presetExt), nil
+}
+
+func (f *FilePresetStore) Get(name string) (*Preset, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrPresetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPreset(data)
+}
+
+// Save 先写临时文件再重命名，避免读到写了一半的文件
+func (f *FilePresetStore) Save(preset *Preset) error {
+	path, err := f.path(preset.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(preset, "", "  ")
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, preset.Name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FilePresetStore) Delete(name string) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *FilePresetStore) List() ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), presetExt) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), presetExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,168 @@
+/**
+ * @Time: 2026/10/19 18:55
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestPreset(t *testing.T) *Preset {
+	p, err := ParsePageParam("rowCount=20&orderStr=createTime:pd:&status=1&age=gte:18&deptId=oreq:3&filter=vip==true,level=gt=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset, err := NewPreset("active-users", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return preset
+}
+
+func TestPresetRoundTrip(t *testing.T) {
+	preset := newTestPreset(t)
+	data, err := preset.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := newTestPreset(t).Marshal()
+	if err != nil || string(again) != string(data) {
+		t.Fatalf("Marshal is not stable:\n%s\n%s", data, again)
+	}
+	decoded, err := UnmarshalPreset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := preset.PageInfo(), decoded.PageInfo()
+	wantWhere, wantArgs := want.Where()
+	gotWhere, gotArgs := got.Where()
+	if gotWhere != wantWhere || !reflect.DeepEqual(gotArgs, wantArgs) {
+		t.Fatalf("decoded Where = %s %v, want %s %v", gotWhere, gotArgs, wantWhere, wantArgs)
+	}
+	if got.RowCount != 20 || got.OrderStr != "create_time desc" {
+		t.Fatalf("decoded page = %d %q", got.RowCount, got.OrderStr)
+	}
+}
+
+func TestUnmarshalPresetNumbers(t *testing.T) {
+	preset, err := UnmarshalPreset([]byte(`{"version":1,"name":"n","and":[{"key":"age >= ?","value":18},{"key":"score < ?","value":9.5}],"filter":{"column":"id","op":"IN","value":[1,2]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preset.And[0].Value != int64(18) || preset.And[1].Value != 9.5 {
+		t.Errorf("and values = %#v %#v", preset.And[0].Value, preset.And[1].Value)
+	}
+	if !reflect.DeepEqual(preset.Filter.Value, []interface{}{int64(1), int64(2)}) {
+		t.Errorf("filter value = %#v", preset.Filter.Value)
+	}
+}
+
+func TestUnmarshalPresetRejects(t *testing.T) {
+	for _, data := range []string{
+		`{"version":2,"name":"n"}`,
+		`{"version":0,"name":"n"}`,
+		`{"version":1,"name":"../etc"}`,
+		`{"version":1,"name":""}`,
+		`{"version":1,"name":"n","filter":{"raw":"1 = 1"}}`,
+		`{"version":1,"name":"n","filter":{"column":"id; drop table","op":"="}}`,
+		`{"version":1,"name":"n","filter":{"column":"id","op":"= 1 OR"}}`,
+		`{"version":1,"name":"n","filter":{"logic":"XOR","children":[{"column":"id","op":"="}]}}`,
+		`{"version":1,"name":"n","and":[{"key":"1 = 1 OR id = ?","value":1}]}`,
+		`not json`,
+	} {
+		if _, err := UnmarshalPreset([]byte(data)); err == nil {
+			t.Errorf("%s: accepted", data)
+		}
+	}
+}
+
+func TestPresetMerge(t *testing.T) {
+	store := NewMemoryPresetStore()
+	if err := store.Save(newTestPreset(t)); err != nil {
+		t.Fatal(err)
+	}
+	SetPresetStore(store)
+	defer SetPresetStore(nil)
+
+	// url 中相同的条件覆盖预设，指定的排序以 url 为准，其余叠加
+	p, err := ParsePageParam("preset=active-users&current=2&status=2&name=lk:a&orderStr=id:pa:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Current != 2 || p.RowCount != 20 || p.OrderStr != "id asc" {
+		t.Errorf("page = %d/%d/%q", p.Current, p.RowCount, p.OrderStr)
+	}
+	want := map[string]interface{}{"status = ?": "2", "age >= ?": "18", "name LIKE ?": "a%"}
+	if !reflect.DeepEqual(p.AndParams, want) {
+		t.Errorf("AndParams = %v, want %v", p.AndParams, want)
+	}
+	if !reflect.DeepEqual(p.OrParams, map[string]interface{}{"dept_id = ?": "3"}) {
+		t.Errorf("OrParams = %v", p.OrParams)
+	}
+	if p.Filter == nil {
+		t.Error("preset filter lost")
+	}
+
+	if _, err = ParsePageParam("preset=missing"); err == nil || !strings.Contains(err.Error(), ErrPresetNotFound.Error()) {
+		t.Errorf("missing preset: err = %v", err)
+	}
+	SetPresetStore(nil)
+	if _, err = ParsePageParam("preset=active-users"); err == nil {
+		t.Error("preset accepted without a store")
+	}
+}
+
+func TestPresetStores(t *testing.T) {
+	fileStore, err := NewFilePresetStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]PresetStore{"memory": NewMemoryPresetStore(), "file": fileStore} {
+		if _, err = store.Get("active-users"); !errors.Is(err, ErrPresetNotFound) {
+			t.Errorf("%s: Get missing err = %v", name, err)
+		}
+		preset := newTestPreset(t)
+		if err = store.Save(preset); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		other := newTestPreset(t)
+		other.Name = "b"
+		if err = store.Save(other); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := store.Get("active-users")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.RowCount != 20 || len(got.And) != 2 {
+			t.Errorf("%s: Get = %+v", name, got)
+		}
+		// 修改读出的预设不影响存储中的数据
+		got.And = nil
+		if again, _ := store.Get("active-users"); len(again.And) != 2 {
+			t.Errorf("%s: store shares the preset", name)
+		}
+		if names, _ := store.List(); !reflect.DeepEqual(names, []string{"active-users", "b"}) {
+			t.Errorf("%s: List = %v", name, names)
+		}
+		if err = store.Delete("b"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err = store.Delete("b"); err != nil {
+			t.Errorf("%s: second Delete err = %v", name, err)
+		}
+		if names, _ := store.List(); !reflect.DeepEqual(names, []string{"active-users"}) {
+			t.Errorf("%s: List after Delete = %v", name, names)
+		}
+		bad := newTestPreset(t)
+		bad.Name = "../x"
+		if err = store.Save(bad); err == nil {
+			t.Errorf("%s: invalid name saved", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/**
+ * @Time: 2026/10/19 17:48
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"net/url"
+	"strings"
+)
+
+// queryScanner 逐个读取url查询参数，不对整个查询串解码和切分
+// 返回的 key 和 value 是原始查询串的子串，需要时再调用 unescape 解码
+type queryScanner struct {
+	rest string
+}
+
+// next 读取下一个 key=value，没有 = 的参数跳过，读完返回 false
+func (s *queryScanner) next() (key, value string, ok bool) {
+	for s.rest != "" {
+		param := s.rest
+		if i := strings.IndexByte(param, '&'); i >= 0 {
+			param, s.rest = param[:i], param[i+1:]
+		} else {
+			s.rest = ""
+		}
+		if i := strings.IndexByte(param, '='); i > 0 {
+			return param[:i], param[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// unescape 只有包含 % 或 + 时才解码，不需要解码时不分配内存
+func unescape(s string) (string, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' || s[i] == '+' {
+			return url.QueryUnescape(s)
+		}
+	}
+	return s, nil
+}
+
+// isODataKey 是否为 OData 系统参数，$ 可能被编码为 %24
+func isODataKey(key string) bool {
+	if strings.HasPrefix(key, "%24") {
+		key = key[3:]
+	} else if strings.HasPrefix(key, "$") {
+		key = key[1:]
+	} else {
+		return false
+	}
+	switch key {
+	case "filter", "orderby", "top", "skip", "count":
+		return true
+	}
+	return false
+}
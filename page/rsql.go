@@ -0,0 +1,373 @@
+/**
+ * @Time: 2026/10/19 17:36
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError 过滤表达式语法错误，Pos 为出错位置的字节下标(从 0 开始)
+type SyntaxError struct {
+
+	/** 表达式原文 */
+	Expr string
+
+	/** 出错位置 */
+	Pos int
+
+	/** 错误描述 */
+	Msg string
+}
+
+// MaxFilterDepth 过滤表达式中括号的最大嵌套层数，解析器是递归实现的，需要限制深度避免栈溢出
+const MaxFilterDepth = 32
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("过滤表达式第 %d 个字符处%s", e.Pos+1, e.Msg)
+}
+
+// rsqlOps RSQL/FIQL 比较符与 SQL 比较符的对应关系
+var rsqlOps = map[string]string{
+	"==":    "=",
+	"!=":    "<>",
+	"=lt=":  "<",
+	"<":     "<",
+	"=le=":  "<=",
+	"<=":    "<=",
+	"=gt=":  ">",
+	">":     ">",
+	"=ge=":  ">=",
+	">=":    ">=",
+	"=in=":  "IN",
+	"=out=": "NOT IN",
+}
+
+// ParseRSQL 解析 RSQL/FIQL 过滤表达式
+// ; 表示且，, 表示或，括号分组，且的优先级高于或，例如 name==abc*;(age>=18,vip==true)
+// == 和 != 的值中 * 为通配符，会转换为 LIKE 和 NOT LIKE
+// 已注册的自定义操作符可以写成 =name=，例如 tags=arr=vip
+// 字段名支持驼峰写法，会转换为下划线列名，括号最多嵌套 MaxFilterDepth 层
+func ParseRSQL(expr string) (*Cond, error) {
+	p := &rsqlParser{expr: expr}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf(p.pos, "表达式不能为空")
+	}
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf(p.pos, "存在多余的字符 %q", p.expr[p.pos:p.pos+1])
+	}
+	return c, nil
+}
+
+type rsqlParser struct {
+	expr  string
+	pos   int
+	depth int
+}
+
+func (p *rsqlParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *rsqlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *rsqlParser) skipSpace() {
+	for !p.eof() && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *rsqlParser) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.expr, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr or = and { "," and }
+func (p *rsqlParser) parseOr() (*Cond, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	conds := []*Cond{first}
+	for {
+		p.skipSpace()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+		c, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return Or(conds...), nil
+}
+
+// parseAnd and = constraint { ";" constraint }
+func (p *rsqlParser) parseAnd() (*Cond, error) {
+	first, err := p.parseConstraint()
+	if err != nil {
+		return nil, err
+	}
+	conds := []*Cond{first}
+	for {
+		p.skipSpace()
+		if p.peek() != ';' {
+			break
+		}
+		p.pos++
+		c, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return And(conds...), nil
+}
+
+// parseConstraint constraint = "(" or ")" | comparison
+func (p *rsqlParser) parseConstraint() (*Cond, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf(p.pos, "缺少条件")
+	}
+	if p.peek() != '(' {
+		return p.parseComparison()
+	}
+	open := p.pos
+	if p.depth >= MaxFilterDepth {
+		return nil, p.errorf(open, "括号嵌套超过 %d 层", MaxFilterDepth)
+	}
+	p.pos++
+	p.depth++
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.depth--
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf(open, "括号未闭合")
+	}
+	p.pos++
+	return c, nil
+}
+
+// parseComparison comparison = selector comparator arguments
+func (p *rsqlParser) parseComparison() (*Cond, error) {
+	start := p.pos
+	for !p.eof() && isSelectorChar(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.errorf(p.pos, "缺少字段名")
+	}
+	column := CamelToCase(p.expr[start:p.pos])
+	p.skipSpace()
+	opPos := p.pos
+	comparator := p.parseComparator()
+	op, ok := rsqlOps[comparator]
+	if !ok && len(comparator) > 2 && comparator[len(comparator)-1] == '=' {
+		// =name= 形式的比较符可以使用已注册的自定义操作符
+		if custom := LookupOperator(comparator[1 : len(comparator)-1]); custom != nil && !custom.Or {
+			return p.parseCustom(custom, column)
+		}
+	}
+	if !ok {
+		if comparator == "" {
+			return nil, p.errorf(opPos, "缺少比较符")
+		}
+		return nil, p.errorf(opPos, "不支持的比较符 %s", comparator)
+	}
+	p.skipSpace()
+	if op == "IN" || op == "NOT IN" {
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return Compare(column, op, values), nil
+	}
+	valuePos := p.pos
+	value, quoted, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" && !quoted {
+		return nil, p.errorf(valuePos, "缺少比较值")
+	}
+	if !quoted && (op == "=" || op == "<>") && strings.Contains(value, "*") {
+		if op == "=" {
+			op = "LIKE"
+		} else {
+			op = "NOT LIKE"
+		}
+		return Compare(column, op, wildcardToLike(value)), nil
+	}
+	return Compare(column, op, value), nil
+}
+
+// parseCustom 使用自定义操作符解析比较值
+func (p *rsqlParser) parseCustom(op *Operator, column string) (*Cond, error) {
+	p.skipSpace()
+	valuePos := p.pos
+	value, quoted, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" && !quoted {
+		return nil, p.errorf(valuePos, "缺少比较值")
+	}
+	c, err := op.Parse(column, value)
+	if err != nil {
+		return nil, p.errorf(valuePos, "比较值不合法：%s", err.Error())
+	}
+	if c == nil {
+		return nil, p.errorf(valuePos, "比较值不合法")
+	}
+	return c, nil
+}
+
+// parseComparator 读取比较符：== != < <= > >= 或 =xx=
+func (p *rsqlParser) parseComparator() string {
+	start := p.pos
+	switch p.peek() {
+	case '<', '>':
+		p.pos++
+		if p.peek() == '=' {
+			p.pos++
+		}
+	case '!':
+		p.pos++
+		if p.peek() == '=' {
+			p.pos++
+		}
+	case '=':
+		p.pos++
+		for !p.eof() && p.peek() >= 'a' && p.peek() <= 'z' {
+			p.pos++
+		}
+		if p.peek() == '=' {
+			p.pos++
+		}
+	}
+	return p.expr[start:p.pos]
+}
+
+// parseValueList 读取 (a,b,c) 形式的值列表
+func (p *rsqlParser) parseValueList() ([]interface{}, error) {
+	if p.peek() != '(' {
+		return nil, p.errorf(p.pos, "=in= 和 =out= 的值必须写成 (a,b,c)")
+	}
+	open := p.pos
+	p.pos++
+	var values []interface{}
+	for {
+		p.skipSpace()
+		valuePos := p.pos
+		value, quoted, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if value == "" && !quoted {
+			return nil, p.errorf(valuePos, "缺少比较值")
+		}
+		values = append(values, value)
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() == ')' {
+			p.pos++
+			return values, nil
+		}
+		return nil, p.errorf(open, "括号未闭合")
+	}
+}
+
+// parseValue 读取一个值，支持单引号或双引号包裹，引号内可用 \ 转义
+func (p *rsqlParser) parseValue() (value string, quoted bool, err error) {
+	quote := p.peek()
+	if quote != '\'' && quote != '"' {
+		start := p.pos
+		for !p.eof() && !isReservedChar(p.peek()) {
+			p.pos++
+		}
+		return p.expr[start:p.pos], false, nil
+	}
+	open := p.pos
+	p.pos++
+	var sb strings.Builder
+	for !p.eof() {
+		ch := p.peek()
+		p.pos++
+		if ch == '\\' && !p.eof() {
+			sb.WriteByte(p.peek())
+			p.pos++
+			continue
+		}
+		if ch == quote {
+			return sb.String(), true, nil
+		}
+		sb.WriteByte(ch)
+	}
+	return "", true, p.errorf(open, "引号未闭合")
+}
+
+// isSelectorChar 字段名只允许字母、数字、下划线和点，避免拼接 SQL 时被注入
+func isSelectorChar(ch byte) bool {
+	return ch == '_' || ch == '.' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+func isReservedChar(ch byte) bool {
+	switch ch {
+	case ';', ',', '(', ')', '"', '\'', ' ', '\t':
+		return true
+	}
+	return false
+}
+
+// wildcardToLike 将 * 通配符转换为 LIKE 模式，原有的 % _ \ 会被转义
+func wildcardToLike(value string) string {
+	return likePattern(value, true)
+}
+
+// escapeLike 转义 LIKE 模式中的 % _ \，* 按普通字符处理
+func escapeLike(value string) string {
+	return likePattern(value, false)
+}
+
+func likePattern(value string, wildcard bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch ch := value[i]; ch {
+		case '*':
+			if wildcard {
+				sb.WriteByte('%')
+			} else {
+				sb.WriteByte(ch)
+			}
+		case '%', '_', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(ch)
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+/**
+ * @Time: 2026/10/19 18:30
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRSQL(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		args []interface{}
+	}{
+		{"name==abc*;(age>=18,vip==true)", "name LIKE ? AND (age >= ? OR vip = ?)", []interface{}{"abc%", "18", "true"}},
+		{"userName!=a*", "user_name NOT LIKE ?", []interface{}{"a%"}},
+		{"id=in=(1,2,'x y')", "id IN (?,?,?)", []interface{}{"1", "2", "x y"}},
+		// 且的优先级高于或
+		{"a==1,b==2;c=out=(3)", "a = ? OR (b = ? AND c NOT IN (?))", []interface{}{"1", "2", "3"}},
+		{`a=="x\"y"`, "a = ?", []interface{}{`x"y`}},
+	}
+	for _, tt := range tests {
+		c, err := ParseRSQL(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		sql, args := c.SQL()
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.expr, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestParseRSQLSyntaxError(t *testing.T) {
+	tests := map[string]int{
+		"":           0,
+		"a":          1,
+		"a==":        3,
+		"(a==1":      0,
+		"a==1)":      4,
+		"a=xx=1":     1,
+		"a==1;;b==2": 5,
+	}
+	for expr, pos := range tests {
+		_, err := ParseRSQL(expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: err = %v, want *SyntaxError", expr, err)
+			continue
+		}
+		if syntaxErr.Pos != pos {
+			t.Errorf("%q: pos = %d, want %d (%v)", expr, syntaxErr.Pos, pos, err)
+		}
+	}
+}
+
+func TestParseRSQLDepth(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "a==1" + strings.Repeat(")", n)
+	}
+	if _, err := ParseRSQL(nested(MaxFilterDepth)); err != nil {
+		t.Fatalf("depth %d: %v", MaxFilterDepth, err)
+	}
+	// 并列的括号不累加深度
+	if _, err := ParseRSQL(strings.TrimSuffix(strings.Repeat(nested(MaxFilterDepth)+";", 3), ";")); err != nil {
+		t.Fatalf("sibling groups: %v", err)
+	}
+	for _, expr := range []string{nested(MaxFilterDepth + 1), strings.Repeat("(", 1<<20)} {
+		_, err := ParseRSQL(expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Pos != MaxFilterDepth {
+			t.Errorf("len %d: err = %v, want *SyntaxError at %d", len(expr), err, MaxFilterDepth)
+		}
+	}
+}
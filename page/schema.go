@@ -0,0 +1,362 @@
+/**
+ * @Time: 2026/10/19 17:35
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+
+	/** ------- 字段数据类型 ------  */
+	TypeString   = "string"
+	TypeInteger  = "integer"
+	TypeNumber   = "number"
+	TypeBoolean  = "boolean"
+	TypeDate     = "date"
+	TypeDateTime = "date-time"
+)
+
+// Field 接口允许查询的字段
+type Field struct {
+
+	/** 字段名，与 url 参数名一致，驼峰写法 */
+	Name string
+
+	/** 数据类型，取值见 TypeString 等常量，默认 string */
+	Type string
+
+	/** 允许的操作符，为空时允许全部操作符 */
+	Ops []string
+
+	/** 是否允许排序 */
+	Sortable bool
+
+	/** 字段说明 */
+	Description string
+
+	/** 示例值，不带操作符前缀 */
+	Example interface{}
+}
+
+// Schema 一个列表接口允许查询的字段白名单
+type Schema struct {
+
+	/** 表名 */
+	Table string
+
+	/** 允许查询的字段 */
+	Fields []Field
+
+	/** 单行数据的模型，例如 User{}，用于生成文档中的响应结构 */
+	Model interface{}
+
+	/** 允许按点号路径过滤的关联实体，例如 customer.city */
+	Relations []Relation
+}
+
+// Relation 允许过滤的关联实体，条件会被转换为 EXISTS 子查询，一对多关联也不会产生重复行
+//
+//	EXISTS (SELECT 1 FROM customer WHERE customer.id = orders.customer_id AND customer.city = ?)
+type Relation struct {
+
+	/** 关联名，即 url 参数中点号前的部分，例如 customer */
+	Name string
+
+	/** 关联表名 */
+	Table string
+
+	/** 主表中的关联列，例如 customer_id */
+	LocalKey string
+
+	/** 关联表中被引用的列，默认 id */
+	ForeignKey string
+
+	/** 关联表中允许过滤的字段 */
+	Fields []Field
+}
+
+// sqlOpNames SQL 比较符对应的操作符名，用于校验 Field.Ops
+var sqlOpNames = map[string]string{
+	"=":           "eq",
+	"<":           "lt",
+	"<=":          "lte",
+	">":           "gt",
+	">=":          "gte",
+	"LIKE":        "lk",
+	"<>":          "ne",
+	"NOT LIKE":    "nlk",
+	"IN":          "in",
+	"NOT IN":      "out",
+	"IS NULL":     "null",
+	"IS NOT NULL": "null",
+}
+
+// PageParam 获取url查询参数并按白名单校验，校验失败返回错误，调用方可直接返回给客户端
+func (s *Schema) PageParam(c *gin.Context) (*PageInfo, error) {
+	pageInfo, err := parsePageParam(c.Request.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Apply(pageInfo); err != nil {
+		return nil, err
+	}
+	if err = ApplyScopes(c, pageInfo); err != nil {
+		return nil, err
+	}
+	return pageInfo, nil
+}
+
+// Apply 按白名单校验客户端传入的条件和排序，并将点号路径的条件转换为 EXISTS 子查询
+// 客户端条件中的 Raw 条件无法校验，会被拒绝
+// 范围条件 Scope 由服务端生成，不做校验；同一个 PageInfo 只能调用一次
+func (s *Schema) Apply(p *PageInfo) error {
+	var err error
+	if p.AndParams, err = s.resolveParams(p.AndParams, ""); err != nil {
+		return err
+	}
+	if p.OrParams, err = s.resolveParams(p.OrParams, "or"); err != nil {
+		return err
+	}
+	if p.Filter, err = s.resolveCond(p.Filter, ""); err != nil {
+		return err
+	}
+	if p.OrFilter, err = s.resolveCond(p.OrFilter, "or"); err != nil {
+		return err
+	}
+	return s.checkOrder(p.OrderStr)
+}
+
+func (s *Schema) resolveParams(params map[string]interface{}, prefix string) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		column, op := splitKey(key)
+		// 自定义操作符生成的 Raw 条件也以原文作为 key，无法按白名单校验
+		if Compare(column, op, nil).Key() != key {
+			return nil, errors.New("条件" + key + "无法校验，不允许查询")
+		}
+		c, err := s.resolve(column, op, prefix, value)
+		if err != nil {
+			return nil, err
+		}
+		resolved[c.Key()] = value
+	}
+	return resolved, nil
+}
+
+func (s *Schema) resolveCond(c *Cond, prefix string) (*Cond, error) {
+	if c == nil {
+		return nil, nil
+	}
+	// 原样输出的 SQL 无法按白名单校验，自定义操作符应返回带 Column 和 Op 的条件并通过 Render 渲染
+	if c.Raw != "" {
+		return nil, errors.New("条件" + c.Raw + "无法校验，不允许查询")
+	}
+	if c.IsLeaf() {
+		resolved, err := s.resolve(c.Column, c.Op, prefix, c.Value)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Not = c.Not
+		return resolved, nil
+	}
+	n := *c
+	n.Children = make([]*Cond, len(c.Children))
+	for i, child := range c.Children {
+		resolved, err := s.resolveCond(child, prefix)
+		if err != nil {
+			return nil, err
+		}
+		n.Children[i] = resolved
+	}
+	return &n, nil
+}
+
+// resolve 校验一个条件，点号路径转换为 EXISTS 子查询
+// 自定义操作符的条件 op 即操作符名称，不需要再加 or 前缀
+func (s *Schema) resolve(column, op, prefix string, value interface{}) (*Cond, error) {
+	name, ok := sqlOpNames[op]
+	if ok {
+		name = prefix + name
+	} else {
+		name = op
+	}
+	dot := strings.IndexByte(column, '.')
+	if dot < 0 {
+		field := s.Field(column)
+		if field == nil {
+			return nil, errors.New("字段" + column + "不允许查询")
+		}
+		if !field.Allow(name) {
+			return nil, errors.New("字段" + column + "不允许使用" + name + "查询")
+		}
+		return Compare(column, op, value), nil
+	}
+	relation := s.Relation(column[:dot])
+	if relation == nil {
+		return nil, errors.New("关联" + column[:dot] + "不允许查询")
+	}
+	field := relation.Field(column[dot+1:])
+	if field == nil {
+		return nil, errors.New("字段" + column + "不允许查询")
+	}
+	if !field.Allow(name) {
+		return nil, errors.New("字段" + column + "不允许使用" + name + "查询")
+	}
+	if !ok {
+		return nil, errors.New("字段" + column + "不支持自定义操作符" + op)
+	}
+	if s.Table == "" {
+		return nil, errors.New("按关联过滤时 Schema.Table 不能为空")
+	}
+	inner := Compare(relation.Table+"."+CamelToCase(column[dot+1:]), op, nil).Key()
+	return &Cond{Raw: relation.exists(s.Table, inner), Value: value}, nil
+}
+
+// checkOrder 排序字段必须是允许排序的本表字段
+func (s *Schema) checkOrder(order string) error {
+	if order == "" {
+		return nil
+	}
+	for _, item := range strings.Split(order, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+		field := s.Field(fields[0])
+		if field == nil || !field.Sortable || strings.Contains(fields[0], ".") {
+			return errors.New("字段" + fields[0] + "不允许排序")
+		}
+		if len(fields) > 2 || (len(fields) == 2 && fields[1] != "asc" && fields[1] != "desc") {
+			return errors.New("排序格式错误：" + item)
+		}
+	}
+	return nil
+}
+
+// Relation 按名称查找关联，找不到返回 nil
+func (s *Schema) Relation(name string) *Relation {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Relations {
+		if s.Relations[i].Name == name {
+			return &s.Relations[i]
+		}
+	}
+	return nil
+}
+
+// Field 按 url 参数名或列名查找关联表的字段，找不到返回 nil
+func (r *Relation) Field(name string) *Field {
+	return (&Schema{Fields: r.Fields}).Field(name)
+}
+
+// exists 生成 EXISTS 子查询，inner 为关联表上的条件
+func (r *Relation) exists(table, inner string) string {
+	foreignKey := r.ForeignKey
+	if foreignKey == "" {
+		foreignKey = "id"
+	}
+	return "EXISTS (SELECT 1 FROM " + r.Table + " WHERE " + r.Table + "." + foreignKey + " = " +
+		table + "." + r.LocalKey + " AND " + inner + ")"
+}
+
+// splitKey 将 "age >= ?" 拆分为列名 age 和比较符 >=
+func splitKey(key string) (column, op string) {
+	key = strings.TrimSpace(strings.TrimSuffix(key, "?"))
+	i := strings.IndexByte(key, ' ')
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i], strings.TrimSpace(key[i+1:])
+}
+
+// checkRelationPaths 没有 Schema 时不允许按关联路径查询和排序，避免客户端引用任意关联表
+func checkRelationPaths(p *PageInfo) error {
+	for _, params := range []map[string]interface{}{p.AndParams, p.OrParams} {
+		for key := range params {
+			if column, _ := splitKey(key); strings.IndexByte(column, '.') >= 0 {
+				return errRelationPath(column)
+			}
+		}
+	}
+	for _, c := range []*Cond{p.Filter, p.OrFilter} {
+		if err := checkCondPaths(c); err != nil {
+			return err
+		}
+	}
+	for _, item := range strings.Split(p.OrderStr, ",") {
+		if fields := strings.Fields(item); len(fields) > 0 && strings.IndexByte(fields[0], '.') >= 0 {
+			return errRelationPath(fields[0])
+		}
+	}
+	return nil
+}
+
+func checkCondPaths(c *Cond) error {
+	if c == nil {
+		return nil
+	}
+	if c.IsLeaf() {
+		if strings.IndexByte(c.Column, '.') >= 0 {
+			return errRelationPath(c.Column)
+		}
+		return nil
+	}
+	for _, child := range c.Children {
+		if err := checkCondPaths(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func errRelationPath(column string) error {
+	return errors.New("关联路径" + column + "需要通过 Schema 校验后才能查询")
+}
+
+// Field 按 url 参数名或列名查找字段，找不到返回 nil
+func (s *Schema) Field(name string) *Field {
+	if s == nil {
+		return nil
+	}
+	column := CamelToCase(name)
+	for i := range s.Fields {
+		if s.Fields[i].Name == name || CamelToCase(s.Fields[i].Name) == column {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Allow 字段是否允许使用该操作符
+func (f *Field) Allow(op string) bool {
+	if f == nil {
+		return false
+	}
+	if len(f.Ops) == 0 {
+		return true
+	}
+	op = strings.TrimSuffix(op, ":")
+	for _, v := range f.Ops {
+		if v == op {
+			return true
+		}
+	}
+	return false
+}
+
+// ops 字段实际允许的操作符
+func (f *Field) ops() []string {
+	if len(f.Ops) == 0 {
+		return OperatorNames()
+	}
+	return f.Ops
+}
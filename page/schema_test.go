@@ -0,0 +1,109 @@
+/**
+ * @Time: 2026/10/19 18:45
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"reflect"
+	"testing"
+)
+
+var orderSchema = &Schema{
+	Table: "orders",
+	Fields: []Field{
+		{Name: "status", Ops: []string{"eq", "in"}},
+		{Name: "createTime", Sortable: true},
+	},
+	Relations: []Relation{
+		{Name: "customer", Table: "customer", LocalKey: "customer_id", Fields: []Field{
+			{Name: "city"},
+			{Name: "level", Ops: []string{"gte"}},
+		}},
+	},
+}
+
+const customerExists = "EXISTS (SELECT 1 FROM customer WHERE customer.id = orders.customer_id AND "
+
+func TestSchemaApply(t *testing.T) {
+	tests := []struct {
+		query string
+		where string
+		args  []interface{}
+		order string
+	}{
+		{"customer.city=abc&status=1&orderStr=createTime:pd:", customerExists + "customer.city = ?) AND status = ?", []interface{}{"abc", "1"}, "create_time desc"},
+		{"filter=customer.level=ge=3,status==2", "(" + customerExists + "customer.level >= ?) OR status = ?)", []interface{}{"3", "2"}, ""},
+		{"filter=customer.city==a*", customerExists + "customer.city LIKE ?)", []interface{}{"a%"}, ""},
+	}
+	for _, tt := range tests {
+		p, err := parsePageParam(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if err = orderSchema.Apply(p); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		where, args := p.Where()
+		if where != tt.where || !reflect.DeepEqual(args, tt.args) || p.OrderStr != tt.order {
+			t.Errorf("%s:\n got %s %v %q\nwant %s %v %q", tt.query, where, args, p.OrderStr, tt.where, tt.args, tt.order)
+		}
+	}
+}
+
+func TestSchemaApplyRejects(t *testing.T) {
+	tests := map[string]string{
+		"age=1":               "字段age不允许查询",
+		"status=gt:1":         "字段status不允许使用gt查询",
+		"customer.level=lt:3": "字段customer.level不允许使用lt查询",
+		"customer.phone=1":    "字段customer.phone不允许查询",
+		"foo.x=1":             "关联foo不允许查询",
+		"orderStr=status:pa:": "字段status不允许排序",
+		"filter=age=gt=1":     "字段age不允许查询",
+	}
+	for query, want := range tests {
+		p, err := parsePageParam(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		if err = orderSchema.Apply(p); err == nil || err.Error() != want {
+			t.Errorf("%s: err = %v, want %s", query, err, want)
+		}
+	}
+}
+
+func TestSchemaPageParam(t *testing.T) {
+	p, err := orderSchema.PageParam(newTestContext("/orders?customer.city=abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where, _ := p.Where(); where != customerExists+"customer.city = ?)" {
+		t.Errorf("Where = %q", where)
+	}
+	if _, err = orderSchema.PageParam(newTestContext("/orders?age=1")); err == nil {
+		t.Error("field outside the whitelist accepted")
+	}
+	// 按关联过滤需要表名来生成子查询
+	noTable := *orderSchema
+	noTable.Table = ""
+	p, _ = parsePageParam("customer.city=abc")
+	if err = noTable.Apply(p); err == nil {
+		t.Error("relation filter accepted without Schema.Table")
+	}
+}
+
+func TestRelationPathWithoutSchema(t *testing.T) {
+	for _, query := range []string{
+		"customer.city=abc",
+		"customer.city=oreq:abc",
+		"filter=customer.city==abc",
+		"orderStr=customer.city:pa:",
+		"$filter=customer/city eq 'abc'",
+		"$orderby=customer/city",
+	} {
+		if _, err := ParsePageParam(query); err == nil {
+			t.Errorf("%s: accepted without a Schema", query)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/**
+ * @Time: 2026/10/19 17:37
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScopeFunc 根据请求上下文生成必须附加的服务端条件，例如租户和数据权限
+// 返回 nil 表示该请求不需要限制，返回错误时整个查询被拒绝
+type ScopeFunc func(c *gin.Context) (*Cond, error)
+
+var (
+	scopeMu sync.RWMutex
+	scopes  = make(map[string]ScopeFunc)
+)
+
+// RegisterScope 注册全局的范围条件，建议在路由初始化时注册
+// PageParam 和 ODataParam 会对每个请求执行全部已注册的 ScopeFunc
+func RegisterScope(name string, fn ScopeFunc) error {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	if scopes[name] != nil {
+		return errors.New(name + "已注册,无法重复注册")
+	}
+	scopes[name] = fn
+	return nil
+}
+
+// UnregisterScope 移除已注册的范围条件
+func UnregisterScope(name string) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	delete(scopes, name)
+}
+
+// ApplyScopes 按名称顺序执行全部已注册的 ScopeFunc，结果合并到 Scope
+// 任意一个返回错误都会中止，调用方应拒绝该请求
+func ApplyScopes(c *gin.Context, p *PageInfo) error {
+	scopeMu.RLock()
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	fns := make([]ScopeFunc, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		fns[i] = scopes[name]
+	}
+	scopeMu.RUnlock()
+	for i, fn := range fns {
+		cond, err := fn(c)
+		if err != nil {
+			return errors.New("范围条件" + names[i] + "异常：" + err.Error())
+		}
+		p.AddScope(cond)
+	}
+	return nil
+}
+
+// AddScope 追加一个服务端范围条件
+// 范围条件与客户端条件分开存放，渲染时始终以 AND 包裹在最外层，客户端无法覆盖或用 OR 绕过
+func (p *PageInfo) AddScope(c *Cond) {
+	p.Scope = And(p.Scope, c)
+}
+
+// ContextScope 生成一个从 gin 上下文取值的等于条件，例如 ContextScope("tenant_id", "tenantId")
+// 上下文中没有该值时返回错误，避免漏掉条件导致越权查询
+func ContextScope(column, key string) ScopeFunc {
+	return func(c *gin.Context) (*Cond, error) {
+		value, ok := c.Get(key)
+		if !ok || value == nil {
+			return nil, errors.New("请求上下文中缺少" + key)
+		}
+		return Compare(column, "=", value), nil
+	}
+}
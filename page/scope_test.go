@@ -0,0 +1,97 @@
+/**
+ * @Time: 2026/10/19 18:40
+ * @Author: agent
+ */
+
+package page
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestContext(target string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestApplyScopes(t *testing.T) {
+	if err := RegisterScope("tenant", ContextScope("tenant_id", "tenantId")); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterScope("tenant")
+	if err := RegisterScope("tenant", ContextScope("tenant_id", "tenantId")); err == nil {
+		t.Fatal("duplicate scope registered")
+	}
+	err := RegisterScope("dept", func(c *gin.Context) (*Cond, error) {
+		if c.GetBool("admin") {
+			return nil, nil
+		}
+		return Compare("dept_id", "IN", []interface{}{1, 2}), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterScope("dept")
+
+	c := newTestContext("/users?status=1&vip=oreq:1")
+	c.Set("tenantId", 7)
+	info := PageParam(c)
+	if info == nil {
+		t.Fatal("PageParam returned nil")
+	}
+	// 范围条件按名称顺序在最外层，客户端的 or 条件无法绕过
+	where, args := info.Where()
+	if where != "dept_id IN (?,?) AND tenant_id = ? AND (status = ? OR vip = ?)" {
+		t.Errorf("Where = %q", where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2, 7, "1", "1"}) {
+		t.Errorf("args = %v", args)
+	}
+
+	// ScopeFunc 返回 nil 时不附加条件
+	c = newTestContext("/users")
+	c.Set("tenantId", 7)
+	c.Set("admin", true)
+	if info = PageParam(c); info == nil {
+		t.Fatal("PageParam returned nil")
+	}
+	if where, _ = info.Where(); where != "tenant_id = ?" {
+		t.Errorf("admin Where = %q", where)
+	}
+
+	// 上下文缺少租户时拒绝整个查询
+	if info = PageParam(newTestContext("/users?status=1")); info != nil {
+		t.Fatalf("missing tenant accepted: %+v", info)
+	}
+}
+
+func TestApplyScopesError(t *testing.T) {
+	if err := RegisterScope("deny", func(*gin.Context) (*Cond, error) {
+		return nil, errors.New("无权限")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterScope("deny")
+	info := &PageInfo{}
+	if err := ApplyScopes(newTestContext("/"), info); err == nil || info.Scope != nil {
+		t.Fatalf("err = %v, scope = %+v", err, info.Scope)
+	}
+}
+
+func TestScopeNotFromClient(t *testing.T) {
+	// 客户端传入的参数只会进入 AndParams 或 Filter，不会写入 Scope
+	info, err := ParsePageParam("scope=tenant_id&filter=tenantId==1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Scope != nil {
+		t.Fatalf("Scope = %+v", info.Scope)
+	}
+}
@@ -0,0 +1,379 @@
+/**
+ * @Time: 2026/10/19 17:54
+ * @Author: agent
+ */
+
+package password
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	argon2idID = "argon2id"
+	scryptID   = "scrypt"
+	pbkdf2ID   = "pbkdf2-sha256"
+	bcryptID   = "bcrypt"
+
+	/** 默认的 bcrypt 成本 */
+	DefaultBcryptCost = 12
+
+	/** bcrypt 只使用密码的前 72 个字节，超过时拒绝而不是静默截断 */
+	maxBcryptLength = 72
+)
+
+// Argon2idParams Argon2id 参数
+type Argon2idParams struct {
+
+	/** 内存，单位 KiB */
+	Memory uint32
+
+	/** 迭代次数 */
+	Iterations uint32
+
+	/** 并行度 */
+	Parallelism uint8
+
+	/** 盐长度 */
+	SaltLength int
+
+	/** 哈希长度 */
+	KeyLength int
+}
+
+// ScryptParams scrypt 参数
+type ScryptParams struct {
+
+	/** CPU/内存成本，必须是 2 的幂 */
+	N int
+
+	/** 块大小 */
+	R int
+
+	/** 并行度 */
+	P int
+
+	/** 盐长度 */
+	SaltLength int
+
+	/** 哈希长度 */
+	KeyLength int
+}
+
+// PBKDF2Params PBKDF2-HMAC-SHA256 参数
+type PBKDF2Params struct {
+
+	/** 迭代次数 */
+	Iterations int
+
+	/** 盐长度 */
+	SaltLength int
+
+	/** 哈希长度 */
+	KeyLength int
+}
+
+var (
+
+	// DefaultArgon2idParams 默认的 Argon2id 参数，64 MiB 内存，3 次迭代
+	DefaultArgon2idParams = Argon2idParams{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32}
+
+	// DefaultScryptParams 默认的 scrypt 参数
+	DefaultScryptParams = ScryptParams{N: 1 << 15, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+
+	// DefaultPBKDF2Params 默认的 PBKDF2 参数
+	DefaultPBKDF2Params = PBKDF2Params{Iterations: 600000, SaltLength: 16, KeyLength: 32}
+)
+
+// NewArgon2id Argon2id，格式为 $argon2id$v=19$m=65536,t=3,p=2$盐$哈希
+func NewArgon2id(p Argon2idParams) Hasher {
+	return &argon2idHasher{p: p}
+}
+
+// NewScrypt scrypt，格式为 $scrypt$ln=15,r=8,p=1$盐$哈希，ln 为 N 以 2 为底的对数
+func NewScrypt(p ScryptParams) Hasher {
+	return &scryptHasher{p: p}
+}
+
+// NewPBKDF2 PBKDF2-HMAC-SHA256，格式为 $pbkdf2-sha256$i=600000$盐$哈希
+func NewPBKDF2(p PBKDF2Params) Hasher {
+	return &pbkdf2Hasher{p: p}
+}
+
+// NewBcrypt bcrypt，使用 bcrypt 自身的 $2a$12$ 格式
+func NewBcrypt(cost int) Hasher {
+	return &bcryptHasher{cost: cost}
+}
+
+type argon2idHasher struct {
+	p Argon2idParams
+}
+
+func (h *argon2idHasher) ID() string {
+	return argon2idID
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.p.Iterations, h.p.Memory, h.p.Parallelism, uint32(h.p.KeyLength))
+	params := "m=" + strconv.FormatUint(uint64(h.p.Memory), 10) +
+		",t=" + strconv.FormatUint(uint64(h.p.Iterations), 10) +
+		",p=" + strconv.Itoa(int(h.p.Parallelism))
+	return encodePHC(argon2idID, "v="+strconv.Itoa(argon2.Version), params, salt, key), nil
+}
+
+func (h *argon2idHasher) decode(encoded string) (Argon2idParams, []byte, []byte, error) {
+	phc, err := decodePHC(encoded, argon2idID)
+	if err != nil {
+		return Argon2idParams{}, nil, nil, err
+	}
+	if phc.version != "v="+strconv.Itoa(argon2.Version) {
+		return Argon2idParams{}, nil, nil, ErrInvalidHash
+	}
+	m, err1 := phc.uint("m", 32)
+	t, err2 := phc.uint("t", 32)
+	p, err3 := phc.uint("p", 8)
+	if err1 != nil || err2 != nil || err3 != nil || t == 0 || p == 0 {
+		return Argon2idParams{}, nil, nil, ErrInvalidHash
+	}
+	params := Argon2idParams{Memory: uint32(m), Iterations: uint32(t), Parallelism: uint8(p), SaltLength: len(phc.salt), KeyLength: len(phc.key)}
+	return params, phc.salt, phc.key, nil
+}
+
+func (h *argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	p, _, _, err := h.decode(encoded)
+	return err != nil || p != h.p
+}
+
+type scryptHasher struct {
+	p ScryptParams
+}
+
+func (h *scryptHasher) ID() string {
+	return scryptID
+}
+
+func (h *scryptHasher) Hash(password string) (string, error) {
+	if h.p.N < 2 || h.p.N&(h.p.N-1) != 0 {
+		return "", errors.New("scrypt 的 N 必须是大于 1 的 2 的幂")
+	}
+	salt, err := randomSalt(h.p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, h.p.N, h.p.R, h.p.P, h.p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	params := "ln=" + strconv.Itoa(bits.TrailingZeros(uint(h.p.N))) +
+		",r=" + strconv.Itoa(h.p.R) +
+		",p=" + strconv.Itoa(h.p.P)
+	return encodePHC(scryptID, "", params, salt, key), nil
+}
+
+func (h *scryptHasher) decode(encoded string) (ScryptParams, []byte, []byte, error) {
+	phc, err := decodePHC(encoded, scryptID)
+	if err != nil {
+		return ScryptParams{}, nil, nil, err
+	}
+	ln, err1 := phc.uint("ln", 8)
+	r, err2 := phc.uint("r", 31)
+	p, err3 := phc.uint("p", 31)
+	if err1 != nil || err2 != nil || err3 != nil || ln < 1 || ln > 30 {
+		return ScryptParams{}, nil, nil, ErrInvalidHash
+	}
+	params := ScryptParams{N: 1 << ln, R: int(r), P: int(p), SaltLength: len(phc.salt), KeyLength: len(phc.key)}
+	return params, phc.salt, phc.key, nil
+}
+
+func (h *scryptHasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, len(key))
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *scryptHasher) NeedsRehash(encoded string) bool {
+	p, _, _, err := h.decode(encoded)
+	return err != nil || p != h.p
+}
+
+type pbkdf2Hasher struct {
+	p PBKDF2Params
+}
+
+func (h *pbkdf2Hasher) ID() string {
+	return pbkdf2ID
+}
+
+func (h *pbkdf2Hasher) Hash(password string) (string, error) {
+	salt, err := randomSalt(h.p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key := pbkdf2.Key([]byte(password), salt, h.p.Iterations, h.p.KeyLength, sha256.New)
+	return encodePHC(pbkdf2ID, "", "i="+strconv.Itoa(h.p.Iterations), salt, key), nil
+}
+
+func (h *pbkdf2Hasher) decode(encoded string) (PBKDF2Params, []byte, []byte, error) {
+	phc, err := decodePHC(encoded, pbkdf2ID)
+	if err != nil {
+		return PBKDF2Params{}, nil, nil, err
+	}
+	i, err := phc.uint("i", 31)
+	if err != nil || i == 0 {
+		return PBKDF2Params{}, nil, nil, ErrInvalidHash
+	}
+	params := PBKDF2Params{Iterations: int(i), SaltLength: len(phc.salt), KeyLength: len(phc.key)}
+	return params, phc.salt, phc.key, nil
+}
+
+func (h *pbkdf2Hasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := pbkdf2.Key([]byte(password), salt, p.Iterations, len(key), sha256.New)
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *pbkdf2Hasher) NeedsRehash(encoded string) bool {
+	p, _, _, err := h.decode(encoded)
+	return err != nil || p != h.p
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) ID() string {
+	return bcryptID
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	if len(password) > maxBcryptLength {
+		return "", errors.New("bcrypt 的密码不能超过72个字节")
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == nil {
+		return true, nil
+	}
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return false, ErrInvalidHash
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// isBcrypt bcrypt 哈希以 $2a$ $2b$ $2y$ 开头
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func randomSalt(n int) ([]byte, error) {
+	if n < 8 {
+		return nil, errors.New("盐长度不能小于8个字节")
+	}
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// phc 解析后的 PHC 字符串 $id[$v=版本]$参数$盐$哈希
+type phc struct {
+	version string
+	params  map[string]string
+	salt    []byte
+	key     []byte
+}
+
+// encodePHC 盐和哈希使用不带填充的标准 base64
+func encodePHC(id, version, params string, salt, key []byte) string {
+	parts := []string{"", id}
+	if version != "" {
+		parts = append(parts, version)
+	}
+	parts = append(parts, params, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return strings.Join(parts, "$")
+}
+
+func decodePHC(encoded, id string) (*phc, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" || parts[1] != id {
+		return nil, ErrInvalidHash
+	}
+	p := &phc{params: make(map[string]string)}
+	parts = parts[2:]
+	if len(parts) == 4 {
+		p.version, parts = parts[0], parts[1:]
+	}
+	if len(parts) != 3 {
+		return nil, ErrInvalidHash
+	}
+	for _, kv := range strings.Split(parts[0], ",") {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			return nil, ErrInvalidHash
+		}
+		p.params[kv[:i]] = kv[i+1:]
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil || len(p.key) == 0 {
+		return nil, ErrInvalidHash
+	}
+	return p, nil
+}
+
+func (p *phc) uint(name string, bitSize int) (uint64, error) {
+	v, ok := p.params[name]
+	if !ok {
+		return 0, ErrInvalidHash
+	}
+	return strconv.ParseUint(v, 10, bitSize)
+}
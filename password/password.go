@@ -0,0 +1,148 @@
+/**
+ * @Time: 2026/10/19 17:54
+ * @Author: agent
+ */
+
+package password
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+var (
+	ErrUnknownHash = errors.New("无法识别的密码哈希格式")
+	ErrInvalidHash = errors.New("密码哈希格式错误")
+)
+
+// Hasher 密码哈希算法，哈希结果为 PHC 字符串格式，包含算法、参数、随机盐和哈希值
+type Hasher interface {
+
+	// ID PHC 字符串中的算法标识，例如 argon2id
+	ID() string
+
+	// Hash 使用随机盐计算密码哈希
+	Hash(password string) (string, error)
+
+	// Verify 按哈希中记录的参数校验密码，与当前配置的参数无关
+	Verify(password, encoded string) (bool, error)
+
+	// NeedsRehash 哈希的参数与当前配置不同时返回 true
+	NeedsRehash(encoded string) bool
+}
+
+// Manager 密码管理，新密码使用 Hasher 计算，校验时按哈希格式自动选择算法
+type Manager struct {
+
+	/** 新密码使用的算法 */
+	Hasher Hasher
+
+	/** 是否接受 sign.SHA256 生成的旧哈希 */
+	AllowLegacy bool
+}
+
+// NewManager 创建密码管理，默认接受旧哈希，用户登录成功后应通过 VerifyAndRehash 升级
+func NewManager(hasher Hasher) *Manager {
+	return &Manager{Hasher: hasher, AllowLegacy: true}
+}
+
+var defaultManager = NewManager(NewArgon2id(DefaultArgon2idParams))
+
+// Hash 使用默认的 Argon2id 计算密码哈希
+func Hash(password string) (string, error) {
+	return defaultManager.Hash(password)
+}
+
+// Verify 校验密码，支持本包的全部算法以及 sign.SHA256 生成的旧哈希
+func Verify(password, encoded string) (bool, error) {
+	return defaultManager.Verify(password, encoded)
+}
+
+// NeedsRehash 哈希不是默认的 Argon2id 或参数与默认值不同时返回 true
+func NeedsRehash(encoded string) bool {
+	return defaultManager.NeedsRehash(encoded)
+}
+
+// VerifyAndRehash 校验密码，通过且需要升级时返回新的哈希，调用方应保存新哈希
+func VerifyAndRehash(password, encoded string) (bool, string, error) {
+	return defaultManager.VerifyAndRehash(password, encoded)
+}
+
+func (m *Manager) Hash(password string) (string, error) {
+	return m.Hasher.Hash(password)
+}
+
+func (m *Manager) Verify(password, encoded string) (bool, error) {
+	if isLegacy(encoded) {
+		if !m.AllowLegacy {
+			return false, ErrUnknownHash
+		}
+		return verifyLegacy(password, encoded), nil
+	}
+	hasher := identify(encoded)
+	if hasher == nil {
+		return false, ErrUnknownHash
+	}
+	return hasher.Verify(password, encoded)
+}
+
+func (m *Manager) NeedsRehash(encoded string) bool {
+	hasher := identify(encoded)
+	if hasher == nil || hasher.ID() != m.Hasher.ID() {
+		return true
+	}
+	return m.Hasher.NeedsRehash(encoded)
+}
+
+// VerifyAndRehash 校验密码，通过且 NeedsRehash 时用当前算法重新计算哈希
+// 旧系统的 sign.SHA256 哈希在用户下次登录时就能透明地升级
+func (m *Manager) VerifyAndRehash(password, encoded string) (bool, string, error) {
+	ok, err := m.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, "", err
+	}
+	if !m.NeedsRehash(encoded) {
+		return true, "", nil
+	}
+	rehashed, err := m.Hash(password)
+	if err != nil {
+		return true, "", err
+	}
+	return true, rehashed, nil
+}
+
+// identify 按哈希格式找到对应的算法，校验时使用哈希中的参数
+func identify(encoded string) Hasher {
+	switch {
+	case isBcrypt(encoded):
+		return NewBcrypt(DefaultBcryptCost)
+	case strings.HasPrefix(encoded, "$"+argon2idID+"$"):
+		return NewArgon2id(DefaultArgon2idParams)
+	case strings.HasPrefix(encoded, "$"+scryptID+"$"):
+		return NewScrypt(DefaultScryptParams)
+	case strings.HasPrefix(encoded, "$"+pbkdf2ID+"$"):
+		return NewPBKDF2(DefaultPBKDF2Params)
+	}
+	return nil
+}
+
+// isLegacy sign.SHA256 的结果是 64 位小写 hex
+func isLegacy(encoded string) bool {
+	if len(encoded) != 64 {
+		return false
+	}
+	for i := 0; i < len(encoded); i++ {
+		c := encoded[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func verifyLegacy(password, encoded string) bool {
+	return subtle.ConstantTimeCompare([]byte(sign.SHA256(password)), []byte(encoded)) == 1
+}
@@ -0,0 +1,137 @@
+/**
+ * @Time: 2026/10/19 17:54
+ * @Author: agent
+ */
+
+package password
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+// 测试使用较小的参数，避免拖慢测试
+var (
+	testArgon2id = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 16}
+	testScrypt   = ScryptParams{N: 16, R: 1, P: 1, SaltLength: 16, KeyLength: 16}
+	testPBKDF2   = PBKDF2Params{Iterations: 10, SaltLength: 16, KeyLength: 16}
+	testBcrypt   = 4
+)
+
+func testHashers() []Hasher {
+	return []Hasher{NewArgon2id(testArgon2id), NewScrypt(testScrypt), NewPBKDF2(testPBKDF2), NewBcrypt(testBcrypt)}
+}
+
+func TestHasherRoundTrip(t *testing.T) {
+	prefixes := []string{"$argon2id$v=19$m=64,t=1,p=1$", "$scrypt$ln=4,r=1,p=1$", "$pbkdf2-sha256$i=10$", "$2a$04$"}
+	for i, h := range testHashers() {
+		encoded, err := h.Hash("密码 123")
+		if err != nil {
+			t.Fatalf("%s: %v", h.ID(), err)
+		}
+		if !strings.HasPrefix(encoded, prefixes[i]) {
+			t.Errorf("%s: encoded = %s", h.ID(), encoded)
+		}
+		if ok, err := h.Verify("密码 123", encoded); !ok || err != nil {
+			t.Errorf("%s: Verify = %v, %v", h.ID(), ok, err)
+		}
+		if ok, err := h.Verify("密码 124", encoded); ok || err != nil {
+			t.Errorf("%s: wrong password = %v, %v", h.ID(), ok, err)
+		}
+		// 随机盐，同一密码两次哈希不同
+		if again, _ := h.Hash("密码 123"); again == encoded {
+			t.Errorf("%s: hash is not salted", h.ID())
+		}
+		if h.NeedsRehash(encoded) {
+			t.Errorf("%s: fresh hash needs rehash", h.ID())
+		}
+	}
+}
+
+// RFC 7914 附录的 PBKDF2-HMAC-SHA256 向量
+func TestPBKDF2Vector(t *testing.T) {
+	key, _ := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+	encoded := encodePHC(pbkdf2ID, "", "i=1", []byte("salt"), key)
+	if ok, err := NewPBKDF2(DefaultPBKDF2Params).Verify("passwd", encoded); !ok || err != nil {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	encoded, _ := NewArgon2id(testArgon2id).Hash("secret")
+	stronger := testArgon2id
+	stronger.Iterations = 2
+	m := NewManager(NewArgon2id(stronger))
+	if !m.NeedsRehash(encoded) {
+		t.Fatal("weaker parameters not detected")
+	}
+	if NewManager(NewArgon2id(testArgon2id)).NeedsRehash(encoded) {
+		t.Fatal("same parameters need rehash")
+	}
+	bcryptHash, _ := NewBcrypt(testBcrypt).Hash("secret")
+	if !m.NeedsRehash(bcryptHash) || !NewBcrypt(5).NeedsRehash(bcryptHash) {
+		t.Fatal("algorithm or cost change not detected")
+	}
+	if !m.NeedsRehash("garbage") {
+		t.Fatal("unknown hash does not need rehash")
+	}
+}
+
+func TestManagerVerify(t *testing.T) {
+	m := NewManager(NewArgon2id(testArgon2id))
+	// 按格式识别算法，与当前配置无关
+	for _, h := range testHashers() {
+		encoded, _ := h.Hash("secret")
+		if ok, err := m.Verify("secret", encoded); !ok || err != nil {
+			t.Errorf("%s: Verify = %v, %v", h.ID(), ok, err)
+		}
+	}
+	if _, err := m.Verify("secret", "$md5$abc"); err != ErrUnknownHash {
+		t.Fatalf("unknown format: err = %v", err)
+	}
+	for _, encoded := range []string{"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5", "$scrypt$ln=99,r=1,p=1$c2FsdHNhbHQ$a2V5", "$pbkdf2-sha256$i=0$c2FsdHNhbHQ$a2V5", "$pbkdf2-sha256$i=1$!!$a2V5", "$2a$04$short"} {
+		if _, err := m.Verify("secret", encoded); err != ErrInvalidHash {
+			t.Errorf("Verify(%q) err = %v", encoded, err)
+		}
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	legacy := sign.SHA256("secret")
+	m := NewManager(NewArgon2id(testArgon2id))
+	if ok, err := m.Verify("secret", legacy); !ok || err != nil {
+		t.Fatalf("legacy Verify = %v, %v", ok, err)
+	}
+	if ok, _ := m.Verify("wrong", legacy); ok {
+		t.Fatal("wrong password accepted for legacy hash")
+	}
+	ok, rehashed, err := m.VerifyAndRehash("secret", legacy)
+	if !ok || err != nil || !strings.HasPrefix(rehashed, "$argon2id$") {
+		t.Fatalf("VerifyAndRehash = %v, %q, %v", ok, rehashed, err)
+	}
+	if ok, rehashed, _ = m.VerifyAndRehash("secret", rehashed); !ok || rehashed != "" {
+		t.Fatalf("upgraded hash rehashed again: %q", rehashed)
+	}
+	if ok, rehashed, _ = m.VerifyAndRehash("wrong", legacy); ok || rehashed != "" {
+		t.Fatal("wrong password rehashed")
+	}
+	m.AllowLegacy = false
+	if _, err = m.Verify("secret", legacy); err != ErrUnknownHash {
+		t.Fatalf("legacy accepted when disabled: %v", err)
+	}
+}
+
+func TestBcryptLimits(t *testing.T) {
+	if _, err := NewBcrypt(testBcrypt).Hash(strings.Repeat("a", 73)); err == nil {
+		t.Fatal("bcrypt silently truncated a long password")
+	}
+	if _, err := NewScrypt(ScryptParams{N: 15, R: 1, P: 1, SaltLength: 16, KeyLength: 16}).Hash("x"); err == nil {
+		t.Fatal("scrypt accepted N that is not a power of two")
+	}
+	if _, err := NewPBKDF2(PBKDF2Params{Iterations: 1, SaltLength: 4, KeyLength: 16}).Hash("x"); err == nil {
+		t.Fatal("short salt accepted")
+	}
+}
@@ -0,0 +1,185 @@
+/**
+ * @Time: 2026/10/19 17:52
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+
+	/** 摘要算法名称，可用于 Digest、Hmac 和请求签名 */
+	AlgSHA1   = "SHA1"
+	AlgSHA256 = "SHA256"
+	AlgSHA384 = "SHA384"
+	AlgSHA512 = "SHA512"
+	AlgSM3    = "SM3"
+
+	/** 仅用于兼容旧系统，不要在新的签名中使用 */
+	AlgMD5 = "MD5"
+
+	/** 签名请求头中的算法名称前缀，例如 HMAC-SM3 */
+	hmacPrefix = "HMAC-"
+
+	/** 签名算法请求头，例如 HMAC-SM3，未设置时为 HMAC-SHA256 */
+	HeaderAlgorithm = "X-Signature-Algorithm"
+)
+
+var (
+	algorithmMu sync.RWMutex
+	algorithms  = map[string]func() hash.Hash{
+		AlgSHA1:   sha1.New,
+		AlgSHA256: sha256.New,
+		AlgSHA384: sha512.New384,
+		AlgSHA512: sha512.New,
+		AlgSM3:    NewSM3,
+		AlgMD5:    md5.New,
+	}
+)
+
+// RegisterAlgorithm 注册摘要算法，名称不区分大小写，不能与已有算法重复
+func RegisterAlgorithm(name string, fn func() hash.Hash) error {
+	if fn == nil {
+		return errors.New("摘要算法不能为空")
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return errors.New("摘要算法名称不能为空")
+	}
+	algorithmMu.Lock()
+	defer algorithmMu.Unlock()
+	if algorithms[name] != nil {
+		return errors.New(name + "已注册,无法重复注册")
+	}
+	algorithms[name] = fn
+	return nil
+}
+
+// LookupAlgorithm 按名称查找摘要算法，名称不区分大小写
+func LookupAlgorithm(name string) (func() hash.Hash, error) {
+	algorithmMu.RLock()
+	defer algorithmMu.RUnlock()
+	fn := algorithms[strings.ToUpper(name)]
+	if fn == nil {
+		return nil, errors.New("不支持的摘要算法：" + name)
+	}
+	return fn, nil
+}
+
+// Algorithms 已注册的全部算法名称，按名称排序
+func Algorithms() []string {
+	algorithmMu.RLock()
+	defer algorithmMu.RUnlock()
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Digest 计算摘要
+func Digest(algorithm string, data []byte) ([]byte, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h := fn()
+	_, _ = h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// DigestHex 计算字符串的摘要并转 hex
+func DigestHex(algorithm, text string) (string, error) {
+	sum, err := Digest(algorithm, []byte(text))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// Hmac 计算 hmac
+func Hmac(algorithm string, message, secret []byte) ([]byte, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(fn, secret)
+	_, _ = h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// HmacHex 计算字符串的 hmac 并转 hex
+func HmacHex(algorithm, message, secret string) (string, error) {
+	sum, err := Hmac(algorithm, []byte(message), []byte(secret))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// HmacBase64 计算字符串的 hmac 并转 base64
+func HmacBase64(algorithm, message, secret string) (string, error) {
+	sum, err := Hmac(algorithm, []byte(message), []byte(secret))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// VerifyHmac 以常量时间比较 hmac，避免通过响应时间猜出签名
+func VerifyHmac(algorithm string, message, secret, mac []byte) (bool, error) {
+	expected, err := Hmac(algorithm, message, secret)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(expected, mac), nil
+}
+
+// VerifyHmacHex 校验 hex 编码的 hmac，大小写不敏感
+func VerifyHmacHex(algorithm, message, secret, signature string) (bool, error) {
+	mac, err := hex.DecodeString(signature)
+	if err != nil {
+		return false, nil
+	}
+	return VerifyHmac(algorithm, []byte(message), []byte(secret), mac)
+}
+
+// VerifyHmacBase64 校验标准 base64 编码的 hmac
+func VerifyHmacBase64(algorithm, message, secret, signature string) (bool, error) {
+	mac, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, nil
+	}
+	return VerifyHmac(algorithm, []byte(message), []byte(secret), mac)
+}
+
+// parseHmacAlgorithm 将签名请求头中的 HMAC-SM3 转为 SM3，为空时为 SHA256
+func parseHmacAlgorithm(value string) (string, error) {
+	if value == "" {
+		return AlgSHA256, nil
+	}
+	upper := strings.ToUpper(value)
+	if !strings.HasPrefix(upper, hmacPrefix) {
+		return "", errors.New("不支持的签名算法：" + value)
+	}
+	name := upper[len(hmacPrefix):]
+	if _, err := LookupAlgorithm(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,272 @@
+/**
+ * @Time: 2026/10/19 17:56
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+
+	/** RSA PKCS#1 v1.5 + SHA-256，即支付宝等平台的 RSA2 */
+	RSASHA256 = "RSA-SHA256"
+
+	/** RSA PSS + SHA-256，盐长度等于摘要长度 */
+	RSAPSSSHA256 = "RSA-PSS-SHA256"
+
+	/** ECDSA P-256 + SHA-256，签名为 ASN.1 DER 格式 */
+	ECDSAP256SHA256 = "ECDSA-P256-SHA256"
+
+	/** Ed25519 */
+	Ed25519 = "ED25519"
+)
+
+var ErrKeyType = errors.New("密钥类型与签名方案不匹配")
+
+// PublicKeyProvider 根据应用标识查询对方的公钥，用于校验非对称签名的请求
+type PublicKeyProvider interface {
+	PublicKey(appKey string) (crypto.PublicKey, error)
+}
+
+// StaticPublicKeys 固定的应用标识和公钥
+type StaticPublicKeys map[string]crypto.PublicKey
+
+func (s StaticPublicKeys) PublicKey(appKey string) (crypto.PublicKey, error) {
+	pub, ok := s[appKey]
+	if !ok || pub == nil {
+		return nil, errors.New("应用" + appKey + "不存在")
+	}
+	return pub, nil
+}
+
+// isAsymmetric 是否为非对称签名方案
+func isAsymmetric(scheme string) bool {
+	switch strings.ToUpper(scheme) {
+	case RSASHA256, RSAPSSSHA256, ECDSAP256SHA256, Ed25519:
+		return true
+	}
+	return false
+}
+
+// SignMessage 使用私钥签名，scheme 为 RSASHA256、RSAPSSSHA256、ECDSAP256SHA256 或 Ed25519
+func SignMessage(scheme string, key crypto.Signer, message []byte) ([]byte, error) {
+	switch strings.ToUpper(scheme) {
+	case RSASHA256, RSAPSSSHA256:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrKeyType
+		}
+		sum := sha256.Sum256(message)
+		if strings.ToUpper(scheme) == RSAPSSSHA256 {
+			return rsa.SignPSS(rand.Reader, priv, crypto.SHA256, sum[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, sum[:])
+	case ECDSAP256SHA256:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != elliptic.P256() {
+			return nil, ErrKeyType
+		}
+		sum := sha256.Sum256(message)
+		return ecdsa.SignASN1(rand.Reader, priv, sum[:])
+	case Ed25519:
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, ErrKeyType
+		}
+		return ed25519.Sign(priv, message), nil
+	}
+	return nil, errors.New("不支持的签名方案：" + scheme)
+}
+
+// VerifyMessage 使用公钥校验签名，校验失败返回 ErrInvalidSignature
+func VerifyMessage(scheme string, pub crypto.PublicKey, message, signature []byte) error {
+	switch strings.ToUpper(scheme) {
+	case RSASHA256, RSAPSSSHA256:
+		key, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyType
+		}
+		sum := sha256.Sum256(message)
+		var err error
+		if strings.ToUpper(scheme) == RSAPSSSHA256 {
+			err = rsa.VerifyPSS(key, crypto.SHA256, sum[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature)
+		}
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case ECDSAP256SHA256:
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok || key.Curve != elliptic.P256() {
+			return ErrKeyType
+		}
+		sum := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(key, sum[:], signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case Ed25519:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return ErrKeyType
+		}
+		if !ed25519.Verify(key, message, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return errors.New("不支持的签名方案：" + scheme)
+}
+
+// SignBase64 签名并转标准 base64
+func SignBase64(scheme string, key crypto.Signer, message string) (string, error) {
+	sig, err := SignMessage(scheme, key, []byte(message))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// SignHex 签名并转 hex
+func SignHex(scheme string, key crypto.Signer, message string) (string, error) {
+	sig, err := SignMessage(scheme, key, []byte(message))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// VerifyBase64 校验标准 base64 编码的签名
+func VerifyBase64(scheme string, pub crypto.PublicKey, message, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return VerifyMessage(scheme, pub, []byte(message), sig)
+}
+
+// VerifyHex 校验 hex 编码的签名
+func VerifyHex(scheme string, pub crypto.PublicKey, message, signature string) error {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return VerifyMessage(scheme, pub, []byte(message), sig)
+}
+
+// ParsePrivateKey 解析私钥，支持 PEM 和 DER，PKCS#1、PKCS#8 和 SEC 1 格式
+// 没有 PEM 头尾的 base64 文本按 DER 处理，对方平台经常这样提供密钥
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	der, err := keyDER(data)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("不支持的私钥类型")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("无法解析私钥")
+}
+
+// ParsePublicKey 解析公钥，支持 PEM 和 DER，PKIX、PKCS#1 格式以及 X.509 证书
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	der, err := keyDER(data)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, errors.New("无法解析公钥")
+}
+
+// LoadPrivateKey 从文件加载私钥
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKey(data)
+}
+
+// LoadPublicKey 从文件加载公钥
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(data)
+}
+
+// keyDER 取出 PEM 中的 DER，不是 PEM 时尝试按 base64 解码，都不是时按 DER 返回
+func keyDER(data []byte) ([]byte, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if strings.Contains(block.Type, "ENCRYPTED") || block.Headers["Proc-Type"] != "" {
+			return nil, errors.New("不支持加密的 PEM 密钥")
+		}
+		return block.Bytes, nil
+	}
+	text := strings.Join(strings.Fields(string(data)), "")
+	if der, err := base64.StdEncoding.DecodeString(text); err == nil && len(der) > 0 {
+		return der, nil
+	}
+	if len(data) == 0 {
+		return nil, errors.New("密钥内容为空")
+	}
+	return data, nil
+}
+
+// SignRequestAsymmetric 使用私钥为请求签名，规范化请求与 hmac 签名相同，签名为标准 base64
+// 服务端需要在 Verifier.Algorithms 中允许该方案并设置 PublicKeys
+func SignRequestAsymmetric(r *http.Request, scheme, appKey string, key crypto.Signer, body []byte, extraHeaders ...string) error {
+	scheme = strings.ToUpper(scheme)
+	if !isAsymmetric(scheme) {
+		return errors.New("不支持的签名方案：" + scheme)
+	}
+	canonical, timestamp, err := prepareRequest(r, appKey, "", HashPayload(body), extraHeaders)
+	if err != nil {
+		return err
+	}
+	signature, err := SignBase64(scheme, key, canonical.StringToSign(scheme, timestamp))
+	if err != nil {
+		return err
+	}
+	r.Header.Set(HeaderSignedHeaders, canonical.SignedHeaders)
+	r.Header.Set(HeaderAlgorithm, scheme)
+	r.Header.Set(HeaderSignature, signature)
+	return nil
+}
@@ -0,0 +1,196 @@
+/**
+ * @Time: 2026/10/19 17:56
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var (
+	testKeysOnce sync.Once
+	testRSAKey   *rsa.PrivateKey
+	testECKey    *ecdsa.PrivateKey
+	testEdKey    ed25519.PrivateKey
+)
+
+// testSigners 各方案的测试私钥，RSA 生成较慢，只生成一次
+func testSigners(t *testing.T) map[string]crypto.Signer {
+	testKeysOnce.Do(func() {
+		var err error
+		if testRSAKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			panic(err)
+		}
+		if testECKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			panic(err)
+		}
+		if _, testEdKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			panic(err)
+		}
+	})
+	return map[string]crypto.Signer{
+		RSASHA256:       testRSAKey,
+		RSAPSSSHA256:    testRSAKey,
+		ECDSAP256SHA256: testECKey,
+		Ed25519:         testEdKey,
+	}
+}
+
+func TestSignVerifyMessage(t *testing.T) {
+	message := []byte("待签名的内容")
+	for scheme, key := range testSigners(t) {
+		signature, err := SignMessage(scheme, key, message)
+		if err != nil {
+			t.Fatalf("%s: %v", scheme, err)
+		}
+		if err = VerifyMessage(scheme, key.Public(), message, signature); err != nil {
+			t.Errorf("%s: Verify = %v", scheme, err)
+		}
+		if err = VerifyMessage(scheme, key.Public(), []byte("其他内容"), signature); err != ErrInvalidSignature {
+			t.Errorf("%s: tampered message err = %v", scheme, err)
+		}
+		encoded, _ := SignBase64(scheme, key, "text")
+		if err = VerifyBase64(scheme, key.Public(), "text", encoded); err != nil {
+			t.Errorf("%s: VerifyBase64 = %v", scheme, err)
+		}
+		encoded, _ = SignHex(scheme, key, "text")
+		if err = VerifyHex(scheme, key.Public(), "text", encoded); err != nil {
+			t.Errorf("%s: VerifyHex = %v", scheme, err)
+		}
+	}
+}
+
+func TestSignMessageKeyType(t *testing.T) {
+	signers := testSigners(t)
+	// 私钥和公钥类型都必须与方案匹配
+	if _, err := SignMessage(RSASHA256, signers[Ed25519], nil); err != ErrKeyType {
+		t.Fatalf("RSA with Ed25519 key: %v", err)
+	}
+	if err := VerifyMessage(ECDSAP256SHA256, signers[RSASHA256].Public(), nil, nil); err != ErrKeyType {
+		t.Fatalf("ECDSA with RSA key: %v", err)
+	}
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := SignMessage(ECDSAP256SHA256, p384, nil); err != ErrKeyType {
+		t.Fatalf("P-384 key accepted for P-256: %v", err)
+	}
+	if _, err := SignMessage("DSA", signers[RSASHA256], nil); err == nil {
+		t.Fatal("unknown scheme accepted")
+	}
+	// PKCS#1 v1.5 的签名不能按 PSS 校验
+	signature, _ := SignMessage(RSASHA256, signers[RSASHA256], []byte("m"))
+	if err := VerifyMessage(RSAPSSSHA256, signers[RSASHA256].Public(), []byte("m"), signature); err != ErrInvalidSignature {
+		t.Fatalf("PKCS#1 v1.5 signature accepted as PSS: %v", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	for scheme, key := range testSigners(t) {
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkix, _ := x509.MarshalPKIXPublicKey(key.Public())
+		privForms := [][]byte{
+			pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+			pkcs8,
+			[]byte(base64.StdEncoding.EncodeToString(pkcs8)),
+		}
+		for i, data := range privForms {
+			priv, err := ParsePrivateKey(data)
+			if err != nil {
+				t.Fatalf("%s form %d: %v", scheme, i, err)
+			}
+			signature, _ := SignMessage(scheme, priv, []byte("m"))
+			pub, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = VerifyMessage(scheme, pub, []byte("m"), signature); err != nil {
+				t.Errorf("%s form %d: %v", scheme, i, err)
+			}
+		}
+	}
+	// PKCS#1 和 SEC 1 格式
+	signers := testSigners(t)
+	if _, err := ParsePrivateKey(x509.MarshalPKCS1PrivateKey(testRSAKey)); err != nil {
+		t.Fatalf("PKCS#1 private key: %v", err)
+	}
+	if _, err := ParsePublicKey(x509.MarshalPKCS1PublicKey(&testRSAKey.PublicKey)); err != nil {
+		t.Fatalf("PKCS#1 public key: %v", err)
+	}
+	sec1, _ := x509.MarshalECPrivateKey(testECKey)
+	if priv, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})); err != nil || priv.Public().(*ecdsa.PublicKey).X.Cmp(signers[ECDSAP256SHA256].Public().(*ecdsa.PublicKey).X) != 0 {
+		t.Fatalf("SEC 1 private key: %v", err)
+	}
+	encrypted := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte{1}})
+	for _, data := range [][]byte{nil, []byte("not a key"), encrypted} {
+		if _, err := ParsePrivateKey(data); err == nil {
+			t.Errorf("ParsePrivateKey(%q) accepted", data)
+		}
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	testSigners(t)
+	dir := t.TempDir()
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(testEdKey)
+	pkix, _ := x509.MarshalPKIXPublicKey(testEdKey.Public())
+	privPath, pubPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "pub.pem")
+	_ = os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600)
+	_ = os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}), 0600)
+	priv, err := LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := LoadPublicKey(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, _ := SignMessage(Ed25519, priv, []byte("m"))
+	if err = VerifyMessage(Ed25519, pub, []byte("m"), signature); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadPublicKey(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Fatal("missing file accepted")
+	}
+}
+
+func TestVerifyAsymmetricRequest(t *testing.T) {
+	signers := testSigners(t)
+	v := NewVerifier(nil)
+	v.Algorithms = []string{ECDSAP256SHA256}
+	v.PublicKeys = StaticPublicKeys{"app": signers[ECDSAP256SHA256].Public()}
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	if err := SignRequestAsymmetric(r, ECDSAP256SHA256, "app", signers[ECDSAP256SHA256], nil); err != nil {
+		t.Fatal(err)
+	}
+	if appKey, err := v.Verify(r); err != nil || appKey != "app" {
+		t.Fatalf("Verify = %q, %v", appKey, err)
+	}
+	// 未允许的方案被拒绝
+	r = httptest.NewRequest(http.MethodPost, "/orders", nil)
+	_ = SignRequestAsymmetric(r, Ed25519, "app", signers[Ed25519], nil)
+	if _, err := v.Verify(r); err == nil {
+		t.Fatal("Ed25519 accepted without being allowed")
+	}
+	if err := SignRequestAsymmetric(r, "HMAC-SHA256", "app", signers[Ed25519], nil); err == nil {
+		t.Fatal("hmac accepted as an asymmetric scheme")
+	}
+}
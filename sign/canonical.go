@@ -0,0 +1,202 @@
+/**
+ * @Time: 2026/10/19 17:50
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+
+	/** 签名算法名称，写入签名串的第一行 */
+	AlgorithmHmacSha256 = "HMAC-SHA256"
+
+	/** 参与签名的请求头列表，小写并以分号分隔，例如 x-app-key;x-nonce;x-timestamp */
+	HeaderSignedHeaders = "X-Signed-Headers"
+)
+
+// DefaultSignedHeaders 必须参与签名的请求头
+var DefaultSignedHeaders = []string{"x-app-key", "x-nonce", "x-timestamp"}
+
+// CanonicalRequest 规范化请求，与 AWS SigV4 的规范请求类似
+// 客户端和服务端按相同规则生成，参数顺序、编码方式和请求头大小写不影响签名
+type CanonicalRequest struct {
+
+	/** 请求方法，大写 */
+	Method string
+
+	/** 路径，按段编码，空路径为 / */
+	URI string
+
+	/** 查询串，按参数名和值排序并编码，没有值的参数保留为 key= */
+	Query string
+
+	/** 参与签名的请求头，每行 名称:值 */
+	Headers string
+
+	/** 参与签名的请求头名称，小写并以分号分隔 */
+	SignedHeaders string
+
+	/** 请求体 sha256 的 hex */
+	PayloadHash string
+}
+
+// NewCanonicalRequest 根据请求生成规范化请求，signedHeaders 为参与签名的请求头，host 取自 r.Host
+// payloadHash 为请求体的 HashPayload 结果
+func NewCanonicalRequest(r *http.Request, signedHeaders []string, payloadHash string) *CanonicalRequest {
+	headers, signed := CanonicalHeaders(r.Header, r.Host, signedHeaders)
+	return &CanonicalRequest{
+		Method:        strings.ToUpper(r.Method),
+		URI:           CanonicalURI(r.URL.EscapedPath()),
+		Query:         CanonicalQuery(r.URL.RawQuery),
+		Headers:       headers,
+		SignedHeaders: signed,
+		PayloadHash:   payloadHash,
+	}
+}
+
+// String 规范化请求文本，各部分以换行连接
+func (c *CanonicalRequest) String() string {
+	return strings.Join([]string{
+		c.Method,
+		c.URI,
+		c.Query,
+		c.Headers,
+		c.SignedHeaders,
+		c.PayloadHash,
+	}, "\n")
+}
+
+// StringToSign 签名串：算法名称、时间戳、规范化请求 sha256 的 hex，以换行连接
+func (c *CanonicalRequest) StringToSign(algorithm, timestamp string) string {
+	sum := sha256.Sum256([]byte(c.String()))
+	return algorithm + "\n" + timestamp + "\n" + hex.EncodeToString(sum[:])
+}
+
+// HashPayload 请求体 sha256 的 hex，空请求体也参与计算
+func HashPayload(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalURI 规范化路径，path 为编码后的路径，先按 / 分段再逐段解码和编码
+// 段内编码过的 %2F 仍编码为 %2F，/a%2Fb 与 /a/b 是不同的路径
+func CanonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments[i] = Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// CanonicalQuery 规范化查询串，参数先解码再按 RFC 3986 编码，按参数名排序，参数名相同按值排序
+func CanonicalQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	type pair struct{ key, value string }
+	var pairs []pair
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, value := param, ""
+		if i := strings.IndexByte(param, '='); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		pairs = append(pairs, pair{Escape(key), Escape(value)})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+	var sb strings.Builder
+	for i, p := range pairs {
+		if i > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(p.key + "=" + p.value)
+	}
+	return sb.String()
+}
+
+// CanonicalHeaders 规范化请求头，名称转小写并排序，值去掉首尾空白并合并连续空格，多个值以逗号连接
+// 返回规范化的请求头文本和以分号连接的请求头名称
+func CanonicalHeaders(header http.Header, host string, names []string) (string, string) {
+	seen := make(map[string]bool, len(names))
+	lower := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		lower = append(lower, name)
+	}
+	sort.Strings(lower)
+	lines := make([]string, len(lower))
+	for i, name := range lower {
+		if name == "host" {
+			lines[i] = name + ":" + strings.TrimSpace(host)
+			continue
+		}
+		values := header.Values(name)
+		trimmed := make([]string, len(values))
+		for j, v := range values {
+			trimmed[j] = strings.Join(strings.Fields(v), " ")
+		}
+		lines[i] = name + ":" + strings.Join(trimmed, ",")
+	}
+	return strings.Join(lines, "\n"), strings.Join(lower, ";")
+}
+
+// Escape 按 RFC 3986 编码，只保留字母、数字和 - _ . ~，其余字节编码为大写的 %XX
+func Escape(s string) string {
+	const hexUpper = "0123456789ABCDEF"
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !unreserved(s[i]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s)+2*n)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if unreserved(c) {
+			b = append(b, c)
+		} else {
+			b = append(b, '%', hexUpper[c>>4], hexUpper[c&15])
+		}
+	}
+	return string(b)
+}
+
+func unreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
@@ -0,0 +1,101 @@
+/**
+ * @Time: 2026/10/19 17:48
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/orders/1", "/orders/1"},
+		{"/a%2Fb", "/a%2Fb"},
+		{"/a%2fb", "/a%2Fb"},
+		{"/%E5%90%8D%E5%AD%97", "/%E5%90%8D%E5%AD%97"},
+		{"/a b", "/a%20b"},
+		{"/a%20b", "/a%20b"},
+		{"/a~b", "/a~b"},
+		{"/bad%zz", "/bad%25zz"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURI(tt.path); got != tt.want {
+			t.Errorf("CanonicalURI(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	// 段内编码的 / 不能与路径分隔符混淆
+	if CanonicalURI("/a%2Fb") == CanonicalURI("/a/b") {
+		t.Fatal("/a%2Fb and /a/b have the same canonical path")
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"b=2&a=1", "a=1&b=2"},
+		{"a=2&a=1", "a=1&a=2"},
+		{"flag&a=1", "a=1&flag="},
+		{"name=%E5%BC%A0+%E4%B8%89", "name=%E5%BC%A0%20%E4%B8%89"},
+		{"q=a%26b%3Dc", "q=a%26b%3Dc"},
+		{"&&a=1&", "a=1"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalQuery(tt.query); got != tt.want {
+			t.Errorf("CanonicalQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-App-Key", "  app ")
+	header.Add("X-Multi", "a   b")
+	header.Add("X-Multi", "c")
+	headers, signed := CanonicalHeaders(header, "example.com", []string{"X-Multi", "host", "x-app-key", "X-APP-KEY"})
+	if signed != "host;x-app-key;x-multi" {
+		t.Fatalf("signed = %q", signed)
+	}
+	if want := "host:example.com\nx-app-key:app\nx-multi:a b,c"; headers != want {
+		t.Fatalf("headers = %q, want %q", headers, want)
+	}
+}
+
+func TestCanonicalRequestEquivalent(t *testing.T) {
+	signed := []string{"x-app-key"}
+	build := func(target string, appKey string) string {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("x-app-key", appKey)
+		return NewCanonicalRequest(r, signed, HashPayload(nil)).String()
+	}
+	// 参数顺序、编码方式和请求头大小写不影响规范化请求
+	a := build("/orders?b=2&a=%E5%BC%A0", "app")
+	b := build("/orders?a=张&b=2", "app")
+	if a != b {
+		t.Fatalf("canonical requests differ:\n%s\n%s", a, b)
+	}
+	if a == build("/orders?a=张&b=3", "app") || a == build("/orders?a=张&b=2", "other") {
+		t.Fatal("different requests have the same canonical request")
+	}
+	if !strings.HasPrefix(a, "GET\n/orders\na=%E5%BC%A0&b=2\nx-app-key:app\nx-app-key\n") {
+		t.Fatalf("canonical request = %q", a)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := Escape("aZ09-_.~ /+*"); got != "aZ09-_.~%20%2F%2B%2A" {
+		t.Fatalf("Escape = %q", got)
+	}
+}
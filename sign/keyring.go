@@ -0,0 +1,310 @@
+/**
+ * @Time: 2026/10/19 17:55
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+
+	/** 密钥 ID 请求头，使用密钥环签名时必须参与签名 */
+	HeaderKeyID = "X-Key-Id"
+)
+
+var (
+	ErrKeyNotFound = errors.New("密钥不存在")
+	ErrKeyExpired  = errors.New("密钥不在有效期内")
+)
+
+// Key 带 ID 和有效期的签名密钥
+type Key struct {
+
+	/** 密钥 ID，签名时随签名一起发送，校验时据此选择密钥 */
+	ID string `json:"id"`
+
+	/** 密钥 */
+	Secret string `json:"secret"`
+
+	/** 生效时间，为空表示立即生效 */
+	NotBefore time.Time `json:"notBefore,omitempty"`
+
+	/** 失效时间，为空表示不失效 */
+	NotAfter time.Time `json:"notAfter,omitempty"`
+}
+
+// Valid 密钥在 t 时刻是否有效
+func (k *Key) Valid(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !t.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// KeyRing 密钥环，签名使用当前密钥，校验时按密钥 ID 选择密钥，轮换期间旧密钥在有效期内仍可校验
+// 轮换步骤：加入新密钥，确认各方都已加载后切换当前密钥，等旧签名全部过期后设置旧密钥的失效时间
+// 零值是没有密钥的密钥环，调用 Replace 后即可使用
+type KeyRing struct {
+	mu     sync.RWMutex
+	active string
+	keys   map[string]*Key
+	now    func() time.Time
+}
+
+// keyRingFile 密钥环文件的 json 格式
+type keyRingFile struct {
+
+	/** 当前用于签名的密钥 ID */
+	Active string `json:"active"`
+
+	/** 全部密钥 */
+	Keys []*Key `json:"keys"`
+}
+
+// NewKeyRing 创建密钥环，active 为签名使用的密钥 ID
+func NewKeyRing(active string, keys ...*Key) (*KeyRing, error) {
+	k := &KeyRing{}
+	if err := k.Replace(active, keys...); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Replace 原子地替换全部密钥，用于热加载
+func (k *KeyRing) Replace(active string, keys ...*Key) error {
+	m := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		if key == nil || key.ID == "" || key.Secret == "" {
+			return errors.New("密钥的 ID 和 Secret 不能为空")
+		}
+		if m[key.ID] != nil {
+			return errors.New("密钥 ID 重复：" + key.ID)
+		}
+		copied := *key
+		m[key.ID] = &copied
+	}
+	if m[active] == nil {
+		return errors.New("当前密钥" + active + "不在密钥环中")
+	}
+	k.mu.Lock()
+	k.active, k.keys = active, m
+	k.mu.Unlock()
+	return nil
+}
+
+// Active 签名使用的当前密钥
+func (k *KeyRing) Active() (*Key, error) {
+	k.mu.RLock()
+	id := k.active
+	k.mu.RUnlock()
+	return k.Get(id)
+}
+
+// Get 按 ID 获取有效的密钥
+func (k *KeyRing) Get(id string) (*Key, error) {
+	k.mu.RLock()
+	key := k.keys[id]
+	k.mu.RUnlock()
+	if key == nil {
+		return nil, ErrKeyNotFound
+	}
+	if !key.Valid(k.clock()) {
+		return nil, ErrKeyExpired
+	}
+	return key, nil
+}
+
+// ValidKeys 当前有效的全部密钥，当前密钥排在最前
+func (k *KeyRing) ValidKeys() []*Key {
+	now := k.clock()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	keys := make([]*Key, 0, len(k.keys))
+	if key := k.keys[k.active]; key != nil && key.Valid(now) {
+		keys = append(keys, key)
+	}
+	for id, key := range k.keys {
+		if id != k.active && key.Valid(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// clock 当前时间，未设置 now 时使用 time.Now
+func (k *KeyRing) clock() time.Time {
+	if k.now == nil {
+		return time.Now()
+	}
+	return k.now()
+}
+
+// Sign 使用当前密钥计算 hmac，返回密钥 ID 和 hex 编码的签名
+func (k *KeyRing) Sign(algorithm, message string) (string, string, error) {
+	key, err := k.Active()
+	if err != nil {
+		return "", "", err
+	}
+	signature, err := HmacHex(algorithm, message, key.Secret)
+	if err != nil {
+		return "", "", err
+	}
+	return key.ID, signature, nil
+}
+
+// Verify 使用 keyID 对应的密钥校验 hex 编码的签名
+func (k *KeyRing) Verify(algorithm, message, keyID, signature string) (bool, error) {
+	key, err := k.Get(keyID)
+	if err != nil {
+		return false, err
+	}
+	return VerifyHmacHex(algorithm, message, key.Secret, signature)
+}
+
+// LoadKeyRing 从 json 文件加载密钥环，格式为 {"active":"k2","keys":[{"id":"k1","secret":"...","notAfter":"2026-01-01T00:00:00Z"}]}
+func LoadKeyRing(path string) (*KeyRing, error) {
+	active, keys, err := readKeyRingFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRing(active, keys...)
+}
+
+func readKeyRingFile(path string) (string, []*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	var f keyRingFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return "", nil, errors.New("密钥环文件格式错误：" + err.Error())
+	}
+	return f.Active, f.Keys, nil
+}
+
+// FileKeyRing 定时检查文件修改时间并重新加载的密钥环
+// 重新加载失败时保留原有密钥并打印日志，不会因为写了一半的文件而丢失密钥
+type FileKeyRing struct {
+	*KeyRing
+	path    string
+	fileMu  sync.Mutex
+	modTime time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewFileKeyRing 加载密钥环文件，并每隔 interval 检查一次是否需要重新加载，interval 不大于 0 时不自动加载
+func NewFileKeyRing(path string, interval time.Duration) (*FileKeyRing, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	ring, err := LoadKeyRing(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &FileKeyRing{KeyRing: ring, path: path, modTime: info.ModTime(), stop: make(chan struct{})}
+	if interval > 0 {
+		go f.watch(interval)
+	}
+	return f, nil
+}
+
+// Reload 立即重新加载密钥环文件
+func (f *FileKeyRing) Reload() error {
+	f.fileMu.Lock()
+	defer f.fileMu.Unlock()
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	active, keys, err := readKeyRingFile(f.path)
+	if err != nil {
+		return err
+	}
+	if err = f.Replace(active, keys...); err != nil {
+		return err
+	}
+	f.modTime = info.ModTime()
+	return nil
+}
+
+// Close 停止自动加载
+func (f *FileKeyRing) Close() {
+	f.once.Do(func() {
+		close(f.stop)
+	})
+}
+
+func (f *FileKeyRing) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(f.path)
+			if err != nil {
+				log.Println("密钥环文件检查失败：" + err.Error())
+				continue
+			}
+			f.fileMu.Lock()
+			changed := !info.ModTime().Equal(f.modTime)
+			f.fileMu.Unlock()
+			if !changed {
+				continue
+			}
+			if err = f.Reload(); err != nil {
+				log.Println("密钥环重新加载失败：" + err.Error())
+				// 记录本次的修改时间，文件再次修改后才重试，避免每次检查都打印日志
+				f.fileMu.Lock()
+				f.modTime = info.ModTime()
+				f.fileMu.Unlock()
+			}
+		}
+	}
+}
+
+// KeyRingSecrets 每个应用一个密钥环，同时实现 SecretProvider 和 KeySecretProvider
+type KeyRingSecrets map[string]*KeyRing
+
+func (s KeyRingSecrets) Secret(appKey string) (string, error) {
+	ring := s[appKey]
+	if ring == nil {
+		return "", errors.New("应用" + appKey + "不存在")
+	}
+	key, err := ring.Active()
+	if err != nil {
+		return "", err
+	}
+	return key.Secret, nil
+}
+
+func (s KeyRingSecrets) KeySecret(appKey, keyID string) (string, error) {
+	ring := s[appKey]
+	if ring == nil {
+		return "", errors.New("应用" + appKey + "不存在")
+	}
+	key, err := ring.Get(keyID)
+	if err != nil {
+		return "", err
+	}
+	return key.Secret, nil
+}
+
+// KeySecretProvider 支持密钥轮换的 SecretProvider，请求带有 X-Key-Id 时按应用标识和密钥 ID 查询密钥
+type KeySecretProvider interface {
+	KeySecret(appKey, keyID string) (string, error)
+}
@@ -0,0 +1,144 @@
+/**
+ * @Time: 2026/10/19 17:55
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeyRingZeroValue(t *testing.T) {
+	var ring KeyRing
+	if keys := ring.ValidKeys(); len(keys) != 0 {
+		t.Fatalf("ValidKeys = %v", keys)
+	}
+	if _, err := ring.Active(); err != ErrKeyNotFound {
+		t.Fatalf("Active = %v", err)
+	}
+	if err := ring.Replace("k1", &Key{ID: "k1", Secret: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := ring.Active(); err != nil || key.ID != "k1" {
+		t.Fatalf("Active after Replace = %v, %v", key, err)
+	}
+}
+
+func TestKeyRingValidity(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	ring, err := NewKeyRing("k2",
+		&Key{ID: "k1", Secret: "s1", NotAfter: now},
+		&Key{ID: "k2", Secret: "s2", NotBefore: now.Add(-time.Hour)},
+		&Key{ID: "k3", Secret: "s3", NotBefore: now.Add(time.Hour)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring.now = func() time.Time { return now }
+	if _, err = ring.Get("k1"); err != ErrKeyExpired {
+		t.Fatalf("expired key: %v", err)
+	}
+	if _, err = ring.Get("k3"); err != ErrKeyExpired {
+		t.Fatalf("future key: %v", err)
+	}
+	if _, err = ring.Get("k4"); err != ErrKeyNotFound {
+		t.Fatalf("missing key: %v", err)
+	}
+	if keys := ring.ValidKeys(); len(keys) != 1 || keys[0].ID != "k2" {
+		t.Fatalf("ValidKeys = %v", keys)
+	}
+	// 轮换期间旧密钥仍可校验，当前密钥排在最前
+	ring.now = func() time.Time { return now.Add(-time.Minute) }
+	if keys := ring.ValidKeys(); len(keys) != 2 || keys[0].ID != "k2" || keys[1].ID != "k1" {
+		t.Fatalf("ValidKeys during rotation = %v", keys)
+	}
+}
+
+func TestKeyRingReplaceErrors(t *testing.T) {
+	var ring KeyRing
+	cases := [][]*Key{
+		{{ID: "k1"}},
+		{nil},
+		{{ID: "k1", Secret: "a"}, {ID: "k1", Secret: "b"}},
+		{{ID: "k2", Secret: "a"}},
+	}
+	for _, keys := range cases {
+		if err := ring.Replace("k1", keys...); err == nil {
+			t.Errorf("Replace(%v) accepted", keys)
+		}
+	}
+	// 替换时复制密钥，调用方之后的修改不影响密钥环
+	key := &Key{ID: "k1", Secret: "s1"}
+	if err := ring.Replace("k1", key); err != nil {
+		t.Fatal(err)
+	}
+	key.Secret = "changed"
+	if got, _ := ring.Get("k1"); got.Secret != "s1" {
+		t.Fatalf("secret = %q", got.Secret)
+	}
+}
+
+func TestKeyRingSignVerify(t *testing.T) {
+	ring, _ := NewKeyRing("k2", &Key{ID: "k1", Secret: "s1"}, &Key{ID: "k2", Secret: "s2"})
+	keyID, signature, err := ring.Sign(AlgSHA256, "message")
+	if err != nil || keyID != "k2" {
+		t.Fatalf("Sign = %s, %v", keyID, err)
+	}
+	if ok, err := ring.Verify(AlgSHA256, "message", keyID, signature); !ok || err != nil {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+	if ok, _ := ring.Verify(AlgSHA256, "message", "k1", signature); ok {
+		t.Fatal("signature accepted with another key")
+	}
+	secrets := KeyRingSecrets{"app": ring}
+	if secret, _ := secrets.Secret("app"); secret != "s2" {
+		t.Fatalf("Secret = %q", secret)
+	}
+	if secret, _ := secrets.KeySecret("app", "k1"); secret != "s1" {
+		t.Fatalf("KeySecret = %q", secret)
+	}
+	if _, err = secrets.KeySecret("other", "k1"); err == nil {
+		t.Fatal("unknown app accepted")
+	}
+}
+
+func TestFileKeyRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"active":"k1","keys":[{"id":"k1","secret":"s1"}]}`)
+	ring, err := NewFileKeyRing(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.Close()
+
+	write(`{"active":"k2","keys":[{"id":"k1","secret":"s1","notAfter":"2000-01-01T00:00:00Z"},{"id":"k2","secret":"s2"}]}`)
+	if err = ring.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := ring.Active(); key.ID != "k2" {
+		t.Fatalf("active = %s", key.ID)
+	}
+	if _, err = ring.Get("k1"); err != ErrKeyExpired {
+		t.Fatalf("k1: %v", err)
+	}
+	// 文件写坏时保留原有密钥
+	write(`{"active":`)
+	if err = ring.Reload(); err == nil {
+		t.Fatal("broken file accepted")
+	}
+	if key, _ := ring.Active(); key == nil || key.ID != "k2" {
+		t.Fatal("keys lost after a failed reload")
+	}
+	if _, err = LoadKeyRing(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("missing file accepted")
+	}
+}
@@ -0,0 +1,133 @@
+/**
+ * @Time: 2026/10/19 17:49
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+
+	/** 内存随机串存储默认最多保存的条数 */
+	DefaultNonceCapacity = 1 << 20
+
+	/** 随机串最大长度 */
+	maxNonceLength = 128
+
+	/** 内存随机串存储的分片数 */
+	nonceShards = 64
+)
+
+var (
+	ErrInvalidNonce   = errors.New("随机串长度不能超过128")
+	ErrReplay         = errors.New("请求已被使用，不能重复提交")
+	ErrNonceStoreFull = errors.New("随机串存储已满")
+)
+
+// NonceStore 随机串存储，用于拒绝重放的请求
+// 外部存储可以用 Redis 的 SET key 1 NX PX ttl 实现
+type NonceStore interface {
+
+	// Use 原子地记录随机串，ttl 内已经使用过时返回 false
+	Use(key string, ttl time.Duration) (bool, error)
+}
+
+// NonceStoreFunc 函数形式的 NonceStore
+type NonceStoreFunc func(key string, ttl time.Duration) (bool, error)
+
+func (f NonceStoreFunc) Use(key string, ttl time.Duration) (bool, error) {
+	return f(key, ttl)
+}
+
+// MemoryNonceStore 分片的内存随机串存储，过期的随机串在写入时按顺序清理
+// 容量用满且没有可清理的随机串时返回 ErrNonceStoreFull，拒绝请求而不是淘汰未过期的随机串，避免被淘汰的请求可以重放
+// 只适合单实例部署，多实例需要使用共享存储
+type MemoryNonceStore struct {
+	shards [nonceShards]nonceShard
+	now    func() time.Time
+}
+
+type nonceShard struct {
+	mu       sync.Mutex
+	capacity int
+	expires  map[string]int64
+	queue    []nonceEntry
+	head     int
+}
+
+type nonceEntry struct {
+	key     string
+	expires int64
+}
+
+// NewMemoryNonceStore 创建内存随机串存储，capacity 为最多保存的条数
+func NewMemoryNonceStore(capacity int) *MemoryNonceStore {
+	if capacity < nonceShards {
+		capacity = nonceShards
+	}
+	s := &MemoryNonceStore{now: time.Now}
+	for i := range s.shards {
+		s.shards[i].capacity = capacity / nonceShards
+		s.shards[i].expires = make(map[string]int64)
+	}
+	return s
+}
+
+func (s *MemoryNonceStore) Use(key string, ttl time.Duration) (bool, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	shard := &s.shards[h.Sum32()%nonceShards]
+	now := s.now().UnixNano()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.purge(now)
+	if expires, ok := shard.expires[key]; ok && expires > now {
+		return false, nil
+	}
+	if len(shard.expires) >= shard.capacity {
+		return false, ErrNonceStoreFull
+	}
+	expires := now + int64(ttl)
+	shard.expires[key] = expires
+	shard.queue = append(shard.queue, nonceEntry{key: key, expires: expires})
+	return true, nil
+}
+
+// Len 当前保存的随机串数量，包含已过期但还未被清理的
+func (s *MemoryNonceStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		n += len(s.shards[i].expires)
+		s.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+// purge 从队首清理已过期的随机串，队列按写入顺序排列，ttl 相同时也是过期顺序
+func (sh *nonceShard) purge(now int64) {
+	for sh.head < len(sh.queue) && sh.queue[sh.head].expires <= now {
+		entry := sh.queue[sh.head]
+		if sh.expires[entry.key] == entry.expires {
+			delete(sh.expires, entry.key)
+		}
+		sh.queue[sh.head] = nonceEntry{}
+		sh.head++
+	}
+	// 已清理的部分超过一半时压缩队列，复用底层数组
+	if sh.head > 0 && sh.head*2 >= len(sh.queue) {
+		n := copy(sh.queue, sh.queue[sh.head:])
+		for i := n; i < len(sh.queue); i++ {
+			sh.queue[i] = nonceEntry{}
+		}
+		sh.queue = sh.queue[:n]
+		sh.head = 0
+	}
+}
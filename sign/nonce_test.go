@@ -0,0 +1,134 @@
+/**
+ * @Time: 2026/10/19 17:49
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestNonceStore 使用可控时钟的内存随机串存储
+func newTestNonceStore(capacity int) (*MemoryNonceStore, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryNonceStore(capacity)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// sameShardKeys 落在同一分片的 n 个 key
+func sameShardKeys(n int) []string {
+	shardOf := func(key string) uint32 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		return h.Sum32() % nonceShards
+	}
+	keys := []string{"k0"}
+	for i := 1; len(keys) < n; i++ {
+		key := "k" + strconv.Itoa(i)
+		if shardOf(key) == shardOf(keys[0]) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestMemoryNonceStoreReplay(t *testing.T) {
+	s, now := newTestNonceStore(DefaultNonceCapacity)
+	if ok, err := s.Use("app:n1", time.Minute); !ok || err != nil {
+		t.Fatalf("first use = %v, %v", ok, err)
+	}
+	if ok, _ := s.Use("app:n1", time.Minute); ok {
+		t.Fatal("replayed nonce accepted")
+	}
+	if ok, _ := s.Use("other:n1", time.Minute); !ok {
+		t.Fatal("same nonce of another app rejected")
+	}
+	// 过期后可以再次使用
+	*now = now.Add(time.Minute)
+	if ok, _ := s.Use("app:n1", time.Minute); !ok {
+		t.Fatal("expired nonce still rejected")
+	}
+	if n := s.Len(); n != 2 {
+		t.Fatalf("Len = %d, want 2 after purge", n)
+	}
+}
+
+func TestMemoryNonceStoreFull(t *testing.T) {
+	// 每个分片只能保存 1 条
+	s, now := newTestNonceStore(nonceShards)
+	keys := sameShardKeys(2)
+	if ok, _ := s.Use(keys[0], time.Minute); !ok {
+		t.Fatal("first nonce rejected")
+	}
+	if ok, err := s.Use(keys[1], time.Minute); ok || err != ErrNonceStoreFull {
+		t.Fatalf("full shard = %v, %v", ok, err)
+	}
+	// 未过期的随机串不会被淘汰
+	if ok, _ := s.Use(keys[0], time.Minute); ok {
+		t.Fatal("nonce evicted while the store was full")
+	}
+	*now = now.Add(time.Minute)
+	if ok, err := s.Use(keys[1], time.Minute); !ok || err != nil {
+		t.Fatalf("after expiry = %v, %v", ok, err)
+	}
+}
+
+func TestMemoryNonceStoreCompact(t *testing.T) {
+	s, now := newTestNonceStore(DefaultNonceCapacity)
+	keys := sameShardKeys(10)
+	for _, key := range keys {
+		s.Use(key, time.Second)
+	}
+	*now = now.Add(time.Second)
+	s.Use(keys[0], time.Second)
+	shard := &s.shards[0]
+	for i := range s.shards {
+		if len(s.shards[i].queue) > 0 {
+			shard = &s.shards[i]
+		}
+	}
+	if shard.head != 0 || len(shard.queue) != 1 || len(shard.expires) != 1 {
+		t.Fatalf("queue not compacted: head %d, queue %d, expires %d", shard.head, len(shard.queue), len(shard.expires))
+	}
+}
+
+func TestMemoryNonceStoreConcurrent(t *testing.T) {
+	s := NewMemoryNonceStore(DefaultNonceCapacity)
+	var accepted int32
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := s.Use("app:same", time.Minute); ok {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 1 {
+		t.Fatalf("nonce accepted %d times", accepted)
+	}
+}
+
+func TestNonceStoreFunc(t *testing.T) {
+	var got string
+	store := NonceStoreFunc(func(key string, ttl time.Duration) (bool, error) {
+		got = key
+		return false, nil
+	})
+	v := &Verifier{Nonces: store}
+	if err := v.useNonce("app", "n1"); err != ErrReplay {
+		t.Fatalf("useNonce = %v", err)
+	}
+	if got != "app:n1" {
+		t.Fatalf("key = %q", got)
+	}
+}
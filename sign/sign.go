@@ -6,7 +6,6 @@
 package sign
 
 import (
-	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
@@ -17,31 +16,51 @@ import (
 
 const salt string = "*$salt@*"
 
-// HmacSha256Base64 计算hmac
+// HmacSha256Base64 计算hmac，出错时返回空串，需要处理错误时使用 HmacSha256
 func HmacSha256Base64(message string, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	_, err := io.WriteString(h, message)
+	sum, err := HmacSha256([]byte(message), []byte(secret))
 	if err != nil {
-		log.Fatalln("计算签名错误：" + err.Error())
+		log.Println("计算签名错误：" + err.Error())
 		return ""
 	}
-	sign := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	return sign
+	return base64.StdEncoding.EncodeToString(sum)
 }
 
-// HmacSha256Hex 字符串计算sha256之后转hex
+// HmacSha256Hex 字符串计算sha256之后转hex，出错时返回空串，需要处理错误时使用 HmacSha256
 func HmacSha256Hex(message string, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	_, err := io.WriteString(h, message)
+	sum, err := HmacSha256([]byte(message), []byte(secret))
 	if err != nil {
-		log.Fatalln("计算签名错误：" + err.Error())
+		log.Println("计算签名错误：" + err.Error())
 		return ""
 	}
-	sign := hex.EncodeToString(h.Sum(nil))
-	return sign
+	return hex.EncodeToString(sum)
+}
+
+// HmacSha256 计算hmac，返回原始字节
+func HmacSha256(message, secret []byte) ([]byte, error) {
+	return Hmac(AlgSHA256, message, secret)
+}
+
+// HmacSha256Reader 流式计算hmac，适合大文件
+func HmacSha256Reader(r io.Reader, secret []byte) ([]byte, error) {
+	return HmacReader(AlgSHA256, r, secret)
+}
+
+// VerifyHmacSha256Hex 校验 HmacSha256Hex 的签名，常量时间比较，不要用 == 比较签名
+func VerifyHmacSha256Hex(message, secret, signature string) bool {
+	ok, _ := VerifyHmacHex(AlgSHA256, message, secret, signature)
+	return ok
+}
+
+// VerifyHmacSha256Base64 校验 HmacSha256Base64 的签名，常量时间比较
+func VerifyHmacSha256Base64(message, secret, signature string) bool {
+	ok, _ := VerifyHmacBase64(AlgSHA256, message, secret, signature)
+	return ok
 }
 
 // SHA256 Sha 算签名
+//
+// Deprecated: 全局固定盐的单次哈希不能用于保存密码，密码请使用 password 包，已有的哈希可以通过 password.VerifyAndRehash 升级
 func SHA256(text string) string {
 	hash := sha256.New()
 	text = salt + text + salt
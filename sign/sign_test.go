@@ -0,0 +1,146 @@
+/**
+ * @Time: 2026/10/19 17:52
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// RFC 4231 测试用例 2
+const (
+	rfc4231Key     = "Jefe"
+	rfc4231Message = "what do ya want for nothing?"
+	rfc4231SHA256  = "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	rfc4231SHA512  = "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"
+)
+
+func TestHmacSha256(t *testing.T) {
+	if got := HmacSha256Hex(rfc4231Message, rfc4231Key); got != rfc4231SHA256 {
+		t.Fatalf("HmacSha256Hex = %s", got)
+	}
+	if got := HmacSha256Base64(rfc4231Message, rfc4231Key); got != "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=" {
+		t.Fatalf("HmacSha256Base64 = %s", got)
+	}
+	sum, err := HmacSha256([]byte(rfc4231Message), []byte(rfc4231Key))
+	if err != nil || hex.EncodeToString(sum) != rfc4231SHA256 {
+		t.Fatalf("HmacSha256 = %x, %v", sum, err)
+	}
+}
+
+func TestHmacAlgorithm(t *testing.T) {
+	got, err := HmacHex("sha512", rfc4231Message, rfc4231Key)
+	if err != nil || got != rfc4231SHA512 {
+		t.Fatalf("HmacHex(sha512) = %s, %v", got, err)
+	}
+	if _, err = HmacHex("SHA3", rfc4231Message, rfc4231Key); err == nil {
+		t.Fatal("unknown algorithm accepted")
+	}
+	if _, err = Digest("nope", nil); err == nil {
+		t.Fatal("Digest accepted an unknown algorithm")
+	}
+	sum, _ := DigestHex(AlgSHA256, "abc")
+	if want := sha256.Sum256([]byte("abc")); sum != hex.EncodeToString(want[:]) {
+		t.Fatalf("DigestHex = %s", sum)
+	}
+}
+
+func TestVerifyHmac(t *testing.T) {
+	if !VerifyHmacSha256Hex(rfc4231Message, rfc4231Key, rfc4231SHA256) {
+		t.Fatal("valid hex signature rejected")
+	}
+	if !VerifyHmacSha256Hex(rfc4231Message, rfc4231Key, strings.ToUpper(rfc4231SHA256)) {
+		t.Fatal("upper-case hex signature rejected")
+	}
+	if !VerifyHmacSha256Base64(rfc4231Message, rfc4231Key, HmacSha256Base64(rfc4231Message, rfc4231Key)) {
+		t.Fatal("valid base64 signature rejected")
+	}
+	for _, signature := range []string{"", "zz", rfc4231SHA256[:62], rfc4231SHA256[:63] + "4"} {
+		if VerifyHmacSha256Hex(rfc4231Message, rfc4231Key, signature) {
+			t.Errorf("signature %q accepted", signature)
+		}
+	}
+	if VerifyHmacSha256Hex(rfc4231Message, "other", rfc4231SHA256) {
+		t.Fatal("signature accepted with another secret")
+	}
+	if _, err := VerifyHmacHex("nope", rfc4231Message, rfc4231Key, rfc4231SHA256); err == nil {
+		t.Fatal("unknown algorithm accepted")
+	}
+}
+
+func TestParseHmacAlgorithm(t *testing.T) {
+	tests := map[string]string{"": AlgSHA256, "HMAC-SHA256": AlgSHA256, "hmac-sm3": AlgSM3}
+	for value, want := range tests {
+		if got, err := parseHmacAlgorithm(value); err != nil || got != want {
+			t.Errorf("parseHmacAlgorithm(%q) = %s, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{"SHA256", "HMAC-NOPE"} {
+		if _, err := parseHmacAlgorithm(value); err == nil {
+			t.Errorf("parseHmacAlgorithm(%q) accepted", value)
+		}
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("读取失败") }
+
+func TestHmacReader(t *testing.T) {
+	sum, err := HmacSha256Reader(strings.NewReader(rfc4231Message), []byte(rfc4231Key))
+	if err != nil || hex.EncodeToString(sum) != rfc4231SHA256 {
+		t.Fatalf("HmacSha256Reader = %x, %v", sum, err)
+	}
+	mac, _ := hex.DecodeString(rfc4231SHA256)
+	if ok, err := VerifyHmacReader(AlgSHA256, strings.NewReader(rfc4231Message), []byte(rfc4231Key), mac); !ok || err != nil {
+		t.Fatalf("VerifyHmacReader = %v, %v", ok, err)
+	}
+	if _, err = HmacReader(AlgSHA256, errReader{}, nil); err == nil {
+		t.Fatal("read error ignored")
+	}
+	digest, err := DigestReader(AlgSHA256, strings.NewReader("abc"))
+	if want := sha256.Sum256([]byte("abc")); err != nil || !bytes.Equal(digest, want[:]) {
+		t.Fatalf("DigestReader = %x, %v", digest, err)
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	hr, err := NewHmacReader(AlgSHA256, strings.NewReader(rfc4231Message), []byte(rfc4231Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied bytes.Buffer
+	if _, err = io.Copy(&copied, hr); err != nil {
+		t.Fatal(err)
+	}
+	if copied.String() != rfc4231Message || hr.Len() != int64(len(rfc4231Message)) {
+		t.Fatalf("copied %q, Len %d", copied.String(), hr.Len())
+	}
+	mac, _ := hex.DecodeString(rfc4231SHA256)
+	if hr.Hex() != rfc4231SHA256 || !hr.Equal(mac) || hr.Equal(mac[:31]) {
+		t.Fatalf("Hex = %s", hr.Hex())
+	}
+	if _, err = NewHashReader("nope", nil); err == nil {
+		t.Fatal("unknown algorithm accepted")
+	}
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	if err := RegisterAlgorithm("sha256", sha256.New); err == nil {
+		t.Fatal("duplicate algorithm registered")
+	}
+	if err := RegisterAlgorithm("", sha256.New); err == nil {
+		t.Fatal("empty name registered")
+	}
+	if err := RegisterAlgorithm("TEST-SHA224", nil); err == nil {
+		t.Fatal("nil hash registered")
+	}
+}
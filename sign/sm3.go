@@ -0,0 +1,165 @@
+/**
+ * @Time: 2026/10/19 17:52
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+
+	/** SM3 摘要长度 */
+	SM3Size = 32
+
+	/** SM3 分组长度 */
+	SM3BlockSize = 64
+)
+
+var sm3IV = [8]uint32{
+	0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600,
+	0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e,
+}
+
+// sm3Digest 国密 SM3 杂凑算法，GB/T 32905-2016
+type sm3Digest struct {
+	h   [8]uint32
+	x   [SM3BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// NewSM3 创建 SM3 摘要
+func NewSM3() hash.Hash {
+	d := new(sm3Digest)
+	d.Reset()
+	return d
+}
+
+// SM3Sum 计算 SM3 摘要
+func SM3Sum(data []byte) [SM3Size]byte {
+	d := new(sm3Digest)
+	d.Reset()
+	_, _ = d.Write(data)
+	var sum [SM3Size]byte
+	d.checkSum(sum[:0])
+	return sum
+}
+
+func (d *sm3Digest) Reset() {
+	d.h = sm3IV
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *sm3Digest) Size() int {
+	return SM3Size
+}
+
+func (d *sm3Digest) BlockSize() int {
+	return SM3BlockSize
+}
+
+func (d *sm3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		if d.nx == SM3BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		p = p[c:]
+	}
+	for len(p) >= SM3BlockSize {
+		d.block(p[:SM3BlockSize])
+		p = p[SM3BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+// Sum 在 b 后追加摘要，不影响当前状态
+func (d *sm3Digest) Sum(b []byte) []byte {
+	c := *d
+	return c.checkSum(b)
+}
+
+// checkSum 按与 SHA-256 相同的方式填充：0x80、若干个 0、64 位大端的消息比特长度
+func (d *sm3Digest) checkSum(b []byte) []byte {
+	length := d.len
+	var pad [SM3BlockSize + 8]byte
+	pad[0] = 0x80
+	if length%64 < 56 {
+		_, _ = d.Write(pad[:56-length%64])
+	} else {
+		_, _ = d.Write(pad[:64+56-length%64])
+	}
+	binary.BigEndian.PutUint64(pad[:8], length<<3)
+	_, _ = d.Write(pad[:8])
+	var out [SM3Size]byte
+	for i, v := range d.h {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+	return append(b, out[:]...)
+}
+
+func sm3P0(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17)
+}
+
+func sm3P1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23)
+}
+
+// block 压缩一个 64 字节的分组
+func (d *sm3Digest) block(p []byte) {
+	var w [68]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[i*4:])
+	}
+	for j := 16; j < 68; j++ {
+		w[j] = sm3P1(w[j-16]^w[j-9]^bits.RotateLeft32(w[j-3], 15)) ^ bits.RotateLeft32(w[j-13], 7) ^ w[j-6]
+	}
+	a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+	for j := 0; j < 64; j++ {
+		var t, ff, gg uint32
+		if j < 16 {
+			t = 0x79cc4519
+			ff = a ^ b ^ c
+			gg = e ^ f ^ g
+		} else {
+			t = 0x7a879d8a
+			ff = (a & b) | (a & c) | (b & c)
+			gg = (e & f) | (^e & g)
+		}
+		a12 := bits.RotateLeft32(a, 12)
+		ss1 := bits.RotateLeft32(a12+e+bits.RotateLeft32(t, j%32), 7)
+		ss2 := ss1 ^ a12
+		tt1 := ff + dd + ss2 + (w[j] ^ w[j+4])
+		tt2 := gg + h + ss1 + w[j]
+		dd = c
+		c = bits.RotateLeft32(b, 9)
+		b = a
+		a = tt1
+		h = g
+		g = bits.RotateLeft32(f, 19)
+		f = e
+		e = sm3P0(tt2)
+	}
+	d.h[0] ^= a
+	d.h[1] ^= b
+	d.h[2] ^= c
+	d.h[3] ^= dd
+	d.h[4] ^= e
+	d.h[5] ^= f
+	d.h[6] ^= g
+	d.h[7] ^= h
+}
@@ -0,0 +1,76 @@
+/**
+ * @Time: 2026/10/19 18:08
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// GB/T 32905-2016 附录 A 的示例
+var sm3Vectors = []struct {
+	in   string
+	want string
+}{
+	{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+	{strings.Repeat("abcd", 16), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+}
+
+func TestSM3Sum(t *testing.T) {
+	for _, v := range sm3Vectors {
+		sum := SM3Sum([]byte(v.in))
+		if got := hex.EncodeToString(sum[:]); got != v.want {
+			t.Errorf("SM3Sum(%q) = %s, want %s", v.in, got, v.want)
+		}
+	}
+}
+
+func TestSM3Streaming(t *testing.T) {
+	for _, v := range sm3Vectors {
+		// 按不同长度分段写入，覆盖分组缓冲跨块的情况
+		for _, chunk := range []int{1, 3, 7, 63, 64, 65} {
+			h := NewSM3()
+			for i := 0; i < len(v.in); i += chunk {
+				end := i + chunk
+				if end > len(v.in) {
+					end = len(v.in)
+				}
+				if _, err := h.Write([]byte(v.in[i:end])); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != v.want {
+				t.Errorf("chunk %d: Sum(%q) = %s, want %s", chunk, v.in, got, v.want)
+			}
+			// Sum 不改变内部状态，可以重复调用
+			if got := hex.EncodeToString(h.Sum(nil)); got != v.want {
+				t.Errorf("chunk %d: second Sum(%q) = %s, want %s", chunk, v.in, got, v.want)
+			}
+			h.Reset()
+			_, _ = h.Write([]byte(v.in))
+			if got := hex.EncodeToString(h.Sum(nil)); got != v.want {
+				t.Errorf("chunk %d: Sum after Reset(%q) = %s, want %s", chunk, v.in, got, v.want)
+			}
+		}
+	}
+}
+
+func TestSM3SumAppends(t *testing.T) {
+	h := NewSM3()
+	_, _ = h.Write([]byte("abc"))
+	prefix := []byte("prefix")
+	out := h.Sum(prefix)
+	if string(out[:len(prefix)]) != "prefix" {
+		t.Fatalf("Sum overwrote the prefix: %q", out[:len(prefix)])
+	}
+	if got := hex.EncodeToString(out[len(prefix):]); got != sm3Vectors[0].want {
+		t.Fatalf("Sum with prefix = %s, want %s", got, sm3Vectors[0].want)
+	}
+	if h.Size() != SM3Size || h.BlockSize() != SM3BlockSize {
+		t.Fatalf("Size/BlockSize = %d/%d", h.Size(), h.BlockSize())
+	}
+}
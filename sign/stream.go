@@ -0,0 +1,106 @@
+/**
+ * @Time: 2026/10/19 17:52
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// DigestReader 流式计算摘要，适合大文件，不需要把内容读入内存
+func DigestReader(algorithm string, r io.Reader) ([]byte, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h := fn()
+	if _, err = io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// HmacReader 流式计算 hmac
+func HmacReader(algorithm string, r io.Reader, secret []byte) ([]byte, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(fn, secret)
+	if _, err = io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// VerifyHmacReader 流式计算 hmac 并以常量时间比较
+func VerifyHmacReader(algorithm string, r io.Reader, secret, mac []byte) (bool, error) {
+	expected, err := HmacReader(algorithm, r, secret)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(expected, mac), nil
+}
+
+// HashReader 读取的同时计算摘要，例如一边把上传的文件写入磁盘一边计算摘要
+//
+//	hr, _ := sign.NewHashReader(sign.AlgSHA256, c.Request.Body)
+//	io.Copy(file, hr)
+//	sum := hr.Hex()
+type HashReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+// NewHashReader 创建边读边计算摘要的 Reader
+func NewHashReader(algorithm string, r io.Reader) (*HashReader, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &HashReader{r: r, h: fn()}, nil
+}
+
+// NewHmacReader 创建边读边计算 hmac 的 Reader
+func NewHmacReader(algorithm string, r io.Reader, secret []byte) (*HashReader, error) {
+	fn, err := LookupAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &HashReader{r: r, h: hmac.New(fn, secret)}, nil
+}
+
+func (hr *HashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	if n > 0 {
+		_, _ = hr.h.Write(p[:n])
+		hr.n += int64(n)
+	}
+	return n, err
+}
+
+// Sum 已读取内容的摘要
+func (hr *HashReader) Sum() []byte {
+	return hr.h.Sum(nil)
+}
+
+// Hex 已读取内容的摘要，hex 编码
+func (hr *HashReader) Hex() string {
+	return hex.EncodeToString(hr.h.Sum(nil))
+}
+
+// Len 已读取的字节数
+func (hr *HashReader) Len() int64 {
+	return hr.n
+}
+
+// Equal 以常量时间比较已读取内容的摘要，用于读完后校验 hmac
+func (hr *HashReader) Equal(mac []byte) bool {
+	return hmac.Equal(hr.h.Sum(nil), mac)
+}
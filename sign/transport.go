@@ -0,0 +1,131 @@
+/**
+ * @Time: 2026/10/19 17:50
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Transport 自动为发出的请求签名的 http.RoundTripper，签名方式与 Verifier 一致
+//
+//	client := &http.Client{Transport: sign.NewTransport("app", "secret", nil)}
+//
+// 请求设置了 GetBody 时（http.NewRequest 传入 bytes.Reader、strings.Reader 等会自动设置）
+// 先流式读取一遍计算摘要，再用 GetBody 重新获取请求体发送，不在内存中缓存
+// 其他请求体读入内存后发送，不能超过 MaxBodySize
+type Transport struct {
+
+	/** 应用标识，即密钥 ID */
+	AppKey string
+
+	/** 签名密钥 */
+	Secret string
+
+	/** 密钥环，设置后使用其中的当前密钥签名并发送密钥 ID，忽略 Secret */
+	Keys *KeyRing
+
+	/** hmac 算法，默认 SHA256 */
+	Algorithm string
+
+	/** 额外参与签名的请求头，例如 content-type */
+	SignedHeaders []string
+
+	/** 需要读入内存的请求体上限，默认 DefaultMaxBodySize */
+	MaxBodySize int64
+
+	/** 实际发送请求的 RoundTripper，默认 http.DefaultTransport */
+	Base http.RoundTripper
+}
+
+// NewTransport 创建签名 RoundTripper，base 为 nil 时使用 http.DefaultTransport
+func NewTransport(appKey, secret string, base http.RoundTripper) *Transport {
+	return &Transport{AppKey: appKey, Secret: secret, Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip 不能修改传入的请求，签名请求头加在副本上
+	signed := req.Clone(req.Context())
+	payloadHash, err := t.payloadHash(req, signed)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	algorithm := t.Algorithm
+	if algorithm == "" {
+		algorithm = AlgSHA256
+	}
+	keyID, secret := "", t.Secret
+	if t.Keys != nil {
+		key, err := t.Keys.Active()
+		if err != nil {
+			if signed.Body != nil {
+				_ = signed.Body.Close()
+			}
+			return nil, err
+		}
+		keyID, secret = key.ID, key.Secret
+	}
+	if err = signRequest(signed, algorithm, t.AppKey, keyID, secret, payloadHash, t.SignedHeaders); err != nil {
+		if signed.Body != nil {
+			_ = signed.Body.Close()
+		}
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// payloadHash 计算请求体摘要，并为副本设置可以发送的请求体
+func (t *Transport) payloadHash(req, signed *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return HashPayload(nil), nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, body)
+		_ = body.Close()
+		if err != nil {
+			return "", err
+		}
+		if signed.Body, err = req.GetBody(); err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	limit := t.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	_ = req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > limit {
+		return "", errors.New("请求体超过签名允许的大小，请设置 GetBody 以流式签名")
+	}
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	signed.ContentLength = int64(len(body))
+	return HashPayload(body), nil
+}
@@ -0,0 +1,139 @@
+/**
+ * @Time: 2026/10/19 17:50
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// verifyingBase 用 Verifier 校验收到的请求，并返回读到的请求体
+func verifyingBase(t *testing.T, v *Verifier) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if _, err := v.Verify(r); err != nil {
+			t.Errorf("Verify: %v", err)
+		}
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(r.Body)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(body))), Request: r}, nil
+	})
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTransportGetBody(t *testing.T) {
+	transport := NewTransport("app", "secret", verifyingBase(t, NewVerifier(testSecrets)))
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/orders?a=1", strings.NewReader(`{"id":1}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != `{"id":1}` {
+		t.Fatalf("sent body = %q", body)
+	}
+	// 签名请求头只加在副本上
+	if req.Header.Get(HeaderSignature) != "" {
+		t.Fatal("RoundTrip modified the caller's request")
+	}
+}
+
+func TestTransportBufferedBody(t *testing.T) {
+	transport := NewTransport("app", "secret", verifyingBase(t, NewVerifier(testSecrets)))
+	body := &closeRecorder{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequest(http.MethodPut, "http://example.com/orders/1", body)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(resp.Body); string(got) != "payload" {
+		t.Fatalf("sent body = %q", got)
+	}
+	if resp.Request.ContentLength != int64(len("payload")) || resp.Request.GetBody == nil {
+		t.Fatalf("ContentLength = %d", resp.Request.ContentLength)
+	}
+	if !body.closed {
+		t.Fatal("original body not closed")
+	}
+}
+
+func TestTransportBodyTooLarge(t *testing.T) {
+	transport := NewTransport("app", "secret", roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatal("oversized request was sent")
+		return nil, nil
+	}))
+	transport.MaxBodySize = 4
+	body := &closeRecorder{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/", body)
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("oversized body accepted")
+	}
+	if !body.closed {
+		t.Fatal("body not closed on error")
+	}
+}
+
+func TestTransportKeyRingAndAlgorithm(t *testing.T) {
+	ring, err := NewKeyRing("k1", &Key{ID: "k1", Secret: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier(KeyRingSecrets{"app": ring})
+	v.Algorithms = []string{AlgSM3}
+	transport := &Transport{AppKey: "app", Keys: ring, Algorithm: AlgSM3, Base: verifyingBase(t, v)}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/orders", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Request.Header.Get(HeaderKeyID) != "k1" || resp.Request.Header.Get(HeaderAlgorithm) != "HMAC-SM3" {
+		t.Fatalf("headers = %v", resp.Request.Header)
+	}
+}
+
+func TestTransportServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SignatureMiddleware(testSecrets))
+	router.POST("/orders", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		result.OkData(string(body), c)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{AppKey: "app", Secret: "secret", SignedHeaders: []string{"content-type"}}}
+	resp, err := client.Post(server.URL+"/orders?name=%E5%BC%A0", "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"code":"0"`) {
+		t.Fatalf("response = %s", body)
+	}
+}
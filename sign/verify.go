@@ -0,0 +1,415 @@
+/**
+ * @Time: 2026/10/19 17:48
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+const (
+
+	/** 应用标识请求头 */
+	HeaderAppKey = "X-App-Key"
+
+	/** 时间戳请求头，unix 秒或毫秒 */
+	HeaderTimestamp = "X-Timestamp"
+
+	/** 随机串请求头 */
+	HeaderNonce = "X-Nonce"
+
+	/** 签名请求头，hex 编码 */
+	HeaderSignature = "X-Signature"
+
+	/** 中间件校验失败时返回给客户端的信息，具体原因只写入日志 */
+	MsgVerifyFailed = "签名校验失败"
+
+	/** 验签通过后 gin 上下文中保存应用标识的 key */
+	ContextAppKey = "signAppKey"
+
+	/** 默认允许的时间偏差 */
+	DefaultSkew = 5 * time.Minute
+
+	/** 默认读取的最大请求体，超过的请求直接拒绝 */
+	DefaultMaxBodySize = 10 << 20
+)
+
+var (
+	ErrMissingHeader    = errors.New("签名请求头不完整")
+	ErrInvalidTimestamp = errors.New("请求时间戳格式错误")
+	ErrExpired          = errors.New("请求时间戳超出允许范围")
+	ErrBodyTooLarge     = errors.New("请求体过大")
+	ErrInvalidSignature = errors.New("签名错误")
+)
+
+// SecretProvider 根据应用标识查询签名密钥，应用不存在或已停用时返回错误
+type SecretProvider interface {
+	Secret(appKey string) (string, error)
+}
+
+// SecretProviderFunc 函数形式的 SecretProvider
+type SecretProviderFunc func(appKey string) (string, error)
+
+func (f SecretProviderFunc) Secret(appKey string) (string, error) {
+	return f(appKey)
+}
+
+// StaticSecrets 固定的应用标识和密钥，适合配置文件中的少量调用方
+type StaticSecrets map[string]string
+
+func (s StaticSecrets) Secret(appKey string) (string, error) {
+	secret, ok := s[appKey]
+	if !ok || secret == "" {
+		return "", errors.New("应用" + appKey + "不存在")
+	}
+	return secret, nil
+}
+
+// Verifier 请求签名校验
+// 签名为 HmacHex(算法, CanonicalRequest.StringToSign("HMAC-"+算法, 时间戳), 密钥)，算法由 X-Signature-Algorithm 指定，默认 HMAC-SHA256
+// X-Signature-Algorithm 为 RSA-SHA256 等非对称方案时，签名为 SignBase64(方案, 私钥, CanonicalRequest.StringToSign(方案, 时间戳))
+// 请求体和规范化请求的摘要固定使用 SHA-256，选择的算法只用于 hmac
+// 参与签名的请求头由 X-Signed-Headers 指定，必须包含 DefaultSignedHeaders，未指定时使用 DefaultSignedHeaders
+type Verifier struct {
+
+	/** 密钥查询 */
+	Secrets SecretProvider
+
+	/** 允许客户端与服务端的时间偏差，默认 DefaultSkew */
+	Skew time.Duration
+
+	/** 允许的 hmac 算法或非对称签名方案，例如 SM3、RSA-SHA256，默认只允许 SHA256 */
+	Algorithms []string
+
+	/** 非对称签名使用的公钥，不使用非对称签名时可以为空 */
+	PublicKeys PublicKeyProvider
+
+	/** 最大请求体，默认 DefaultMaxBodySize */
+	MaxBodySize int64
+
+	/** 随机串存储，用于拒绝重放，为 nil 时不校验随机串是否重复 */
+	Nonces NonceStore
+
+	/** 当前时间，默认 time.Now */
+	Now func() time.Time
+}
+
+// NewVerifier 创建请求签名校验，默认使用内存随机串存储，多实例部署时应替换为共享存储
+func NewVerifier(secrets SecretProvider) *Verifier {
+	return &Verifier{
+		Secrets:     secrets,
+		Skew:        DefaultSkew,
+		MaxBodySize: DefaultMaxBodySize,
+		Nonces:      NewMemoryNonceStore(DefaultNonceCapacity),
+	}
+}
+
+// Verify 校验请求签名，成功返回应用标识
+// 请求体会被读取后重新放回，后续处理可以正常读取
+func (v *Verifier) Verify(r *http.Request) (string, error) {
+	appKey := r.Header.Get(HeaderAppKey)
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+	signature := r.Header.Get(HeaderSignature)
+	if appKey == "" || timestamp == "" || nonce == "" || signature == "" {
+		return "", ErrMissingHeader
+	}
+	if len(nonce) > maxNonceLength {
+		return "", ErrInvalidNonce
+	}
+	if err := v.checkTimestamp(timestamp); err != nil {
+		return "", err
+	}
+	algorithm, err := v.algorithm(r.Header.Get(HeaderAlgorithm))
+	if err != nil {
+		return "", err
+	}
+	signedHeaders, err := requestSignedHeaders(r)
+	if err != nil {
+		return "", err
+	}
+	body, err := v.readBody(r)
+	if err != nil {
+		return "", err
+	}
+	canonical := NewCanonicalRequest(r, signedHeaders, HashPayload(body))
+	if isAsymmetric(algorithm) {
+		err = v.verifyAsymmetric(algorithm, appKey, canonical.StringToSign(algorithm, timestamp), signature)
+	} else {
+		err = v.verifyHmac(r, algorithm, appKey, canonical.StringToSign(hmacPrefix+algorithm, timestamp), signature)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err = v.useNonce(appKey, nonce); err != nil {
+		return "", err
+	}
+	return appKey, nil
+}
+
+// useNonce 签名通过后再记录随机串，未通过签名的请求不会占用随机串
+// 时间戳在当前时间前后 Skew 内都有效，随机串需要保存 2 倍 Skew
+func (v *Verifier) useNonce(appKey, nonce string) error {
+	if v.Nonces == nil {
+		return nil
+	}
+	ok, err := v.Nonces.Use(appKey+":"+nonce, 2*v.skew())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReplay
+	}
+	return nil
+}
+
+func (v *Verifier) skew() time.Duration {
+	if v.Skew <= 0 {
+		return DefaultSkew
+	}
+	return v.Skew
+}
+
+func (v *Verifier) verifyHmac(r *http.Request, algorithm, appKey, stringToSign, signature string) error {
+	secret, err := v.secret(r, appKey)
+	if err != nil {
+		return err
+	}
+	ok, err := VerifyHmacHex(algorithm, stringToSign, secret, signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (v *Verifier) verifyAsymmetric(scheme, appKey, stringToSign, signature string) error {
+	if v.PublicKeys == nil {
+		return errors.New("未配置公钥，不支持" + scheme + "签名")
+	}
+	pub, err := v.PublicKeys.PublicKey(appKey)
+	if err != nil {
+		return err
+	}
+	return VerifyBase64(scheme, pub, stringToSign, signature)
+}
+
+// secret 查询密钥，请求带有 X-Key-Id 时按密钥 ID 查询
+func (v *Verifier) secret(r *http.Request, appKey string) (string, error) {
+	if v.Secrets == nil {
+		return "", errors.New("未配置密钥，不支持 hmac 签名")
+	}
+	keyID := r.Header.Get(HeaderKeyID)
+	if keyID == "" {
+		return v.Secrets.Secret(appKey)
+	}
+	provider, ok := v.Secrets.(KeySecretProvider)
+	if !ok {
+		return "", errors.New("不支持按密钥 ID 查询密钥")
+	}
+	return provider.KeySecret(appKey, keyID)
+}
+
+// algorithm 解析签名算法并检查是否允许
+func (v *Verifier) algorithm(value string) (string, error) {
+	algorithm := strings.ToUpper(value)
+	if !isAsymmetric(algorithm) {
+		var err error
+		if algorithm, err = parseHmacAlgorithm(value); err != nil {
+			return "", err
+		}
+	}
+	allowed := v.Algorithms
+	if len(allowed) == 0 {
+		allowed = []string{AlgSHA256}
+	}
+	for _, name := range allowed {
+		if strings.EqualFold(name, algorithm) {
+			return algorithm, nil
+		}
+	}
+	return "", errors.New("不允许的签名算法：" + value)
+}
+
+// requestSignedHeaders 读取参与签名的请求头，必须包含 DefaultSignedHeaders
+// 请求带有 X-Key-Id 时密钥 ID 也必须参与签名，避免被替换为同一应用的其他密钥
+func requestSignedHeaders(r *http.Request) ([]string, error) {
+	required := DefaultSignedHeaders
+	if r.Header.Get(HeaderKeyID) != "" {
+		required = append(append([]string{}, required...), strings.ToLower(HeaderKeyID))
+	}
+	value := r.Header.Get(HeaderSignedHeaders)
+	if value == "" {
+		return required, nil
+	}
+	names := strings.Split(strings.ToLower(value), ";")
+	for _, name := range required {
+		if !containsHeader(names, name) {
+			return nil, errors.New("请求头" + name + "必须参与签名")
+		}
+	}
+	return names, nil
+}
+
+func containsHeader(names []string, name string) bool {
+	for _, n := range names {
+		if strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SignRequest 为请求添加签名请求头，body 为请求体，extraHeaders 为额外参与签名的请求头，例如 content-type
+// 请求体需要调用方自行设置，签名不会读取 r.Body，发送请求时可以使用 Transport 自动签名
+func SignRequest(r *http.Request, appKey, secret string, body []byte, extraHeaders ...string) error {
+	return signRequest(r, AlgSHA256, appKey, "", secret, HashPayload(body), extraHeaders)
+}
+
+// SignRequestAlgorithm 使用指定的 hmac 算法为请求签名，例如 SM3，服务端需要在 Verifier.Algorithms 中允许该算法
+func SignRequestAlgorithm(r *http.Request, algorithm, appKey, secret string, body []byte, extraHeaders ...string) error {
+	return signRequest(r, algorithm, appKey, "", secret, HashPayload(body), extraHeaders)
+}
+
+// SignRequestKeyRing 使用密钥环的当前密钥签名，密钥 ID 通过 X-Key-Id 发送并参与签名
+func SignRequestKeyRing(r *http.Request, appKey string, ring *KeyRing, body []byte, extraHeaders ...string) error {
+	key, err := ring.Active()
+	if err != nil {
+		return err
+	}
+	return signRequest(r, AlgSHA256, appKey, key.ID, key.Secret, HashPayload(body), extraHeaders)
+}
+
+func signRequest(r *http.Request, algorithm, appKey, keyID, secret, payloadHash string, extraHeaders []string) error {
+	algorithm = strings.ToUpper(algorithm)
+	if _, err := LookupAlgorithm(algorithm); err != nil {
+		return err
+	}
+	canonical, timestamp, err := prepareRequest(r, appKey, keyID, payloadHash, extraHeaders)
+	if err != nil {
+		return err
+	}
+	signature, err := HmacHex(algorithm, canonical.StringToSign(hmacPrefix+algorithm, timestamp), secret)
+	if err != nil {
+		return err
+	}
+	r.Header.Set(HeaderSignedHeaders, canonical.SignedHeaders)
+	r.Header.Set(HeaderAlgorithm, hmacPrefix+algorithm)
+	r.Header.Set(HeaderSignature, signature)
+	return nil
+}
+
+// prepareRequest 设置应用标识、时间戳、随机串和密钥 ID 请求头，返回规范化请求和时间戳
+func prepareRequest(r *http.Request, appKey, keyID, payloadHash string, extraHeaders []string) (*CanonicalRequest, string, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(HeaderAppKey, appKey)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderNonce, nonce)
+	signedHeaders := append(append([]string{}, DefaultSignedHeaders...), extraHeaders...)
+	if keyID != "" {
+		r.Header.Set(HeaderKeyID, keyID)
+		signedHeaders = append(signedHeaders, HeaderKeyID)
+	} else {
+		r.Header.Del(HeaderKeyID)
+	}
+	return NewCanonicalRequest(r, signedHeaders, payloadHash), timestamp, nil
+}
+
+// newNonce 16 字节的随机串，hex 编码
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkTimestamp 时间戳与当前时间的偏差不能超过 Skew，大于 1e12 的按毫秒处理
+func (v *Verifier) checkTimestamp(timestamp string) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || ts <= 0 {
+		return ErrInvalidTimestamp
+	}
+	var t time.Time
+	if ts > 1e12 {
+		t = time.Unix(0, ts*int64(time.Millisecond))
+	} else {
+		t = time.Unix(ts, 0)
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	skew := v.skew()
+	diff := now().Sub(t)
+	if diff > skew || diff < -skew {
+		return ErrExpired
+	}
+	return nil
+}
+
+// readBody 读取请求体并放回
+func (v *Verifier) readBody(r *http.Request) ([]byte, error) {
+	return readRequestBody(r, v.MaxBodySize)
+}
+
+// readRequestBody 读取不超过 limit 的请求体并放回 r.Body，limit 不大于 0 时使用 DefaultMaxBodySize
+func readRequestBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Middleware gin 中间件，校验失败时以统一结构返回错误并中止请求，成功时在上下文中保存应用标识
+// 返回给客户端的只有统一的失败信息，具体原因写入日志，避免通过错误信息探测应用标识和密钥 ID
+func (v *Verifier) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		appKey, err := v.Verify(c.Request)
+		if err != nil {
+			log.Println("签名校验失败：" + err.Error())
+			result.FailMsg(MsgVerifyFailed, c)
+			c.Abort()
+			return
+		}
+		c.Set(ContextAppKey, appKey)
+		c.Next()
+	}
+}
+
+// SignatureMiddleware 使用默认配置的签名校验中间件
+func SignatureMiddleware(secrets SecretProvider) gin.HandlerFunc {
+	return NewVerifier(secrets).Middleware()
+}
@@ -0,0 +1,163 @@
+/**
+ * @Time: 2026/10/19 17:48
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+var testSecrets = StaticSecrets{"app": "secret"}
+
+func newSignedRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/orders?b=2&a=1", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if err := SignRequest(r, "app", "secret", []byte(body), "content-type"); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	r := newSignedRequest(t, `{"id":1}`)
+	appKey, err := NewVerifier(testSecrets).Verify(r)
+	if err != nil || appKey != "app" {
+		t.Fatalf("Verify = %q, %v", appKey, err)
+	}
+	// 请求体放回后仍可读取
+	body, _ := io.ReadAll(r.Body)
+	if string(body) != `{"id":1}` {
+		t.Fatalf("body after Verify = %q", body)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *http.Request)
+		want   error
+	}{
+		{"missing signature", func(r *http.Request) { r.Header.Del(HeaderSignature) }, ErrMissingHeader},
+		{"tampered body", func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"id":2}`)) }, ErrInvalidSignature},
+		{"tampered query", func(r *http.Request) { r.URL.RawQuery = "a=1&b=3" }, ErrInvalidSignature},
+		{"tampered signed header", func(r *http.Request) { r.Header.Set("Content-Type", "text/plain") }, ErrInvalidSignature},
+		{"bad timestamp", func(r *http.Request) { r.Header.Set(HeaderTimestamp, "abc") }, ErrInvalidTimestamp},
+		{"long nonce", func(r *http.Request) { r.Header.Set(HeaderNonce, strings.Repeat("n", 129)) }, ErrInvalidNonce},
+	}
+	for _, tt := range tests {
+		r := newSignedRequest(t, `{"id":1}`)
+		tt.modify(r)
+		if _, err := NewVerifier(testSecrets).Verify(r); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyTimestampAndReplay(t *testing.T) {
+	v := NewVerifier(testSecrets)
+	r := newSignedRequest(t, "")
+	v.Now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	if _, err := v.Verify(r); err != ErrExpired {
+		t.Fatalf("expired request: err = %v", err)
+	}
+	v.Now = nil
+	if _, err := v.Verify(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(r); err != ErrReplay {
+		t.Fatalf("replayed request: err = %v", err)
+	}
+}
+
+func TestVerifyAlgorithm(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	if err := SignRequestAlgorithm(r, AlgSM3, "app", "secret", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewVerifier(testSecrets).Verify(r); err == nil {
+		t.Fatal("SM3 accepted without being allowed")
+	}
+	v := NewVerifier(testSecrets)
+	v.Algorithms = []string{AlgSHA256, AlgSM3}
+	if _, err := v.Verify(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestSignedHeadersKeyID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyID, "k1")
+	names, err := requestSignedHeaders(r)
+	if err != nil || !containsHeader(names, "x-key-id") {
+		t.Fatalf("default signed headers = %v, %v", names, err)
+	}
+	r.Header.Set(HeaderSignedHeaders, "x-app-key;x-nonce;x-timestamp")
+	if _, err = requestSignedHeaders(r); err == nil {
+		t.Fatal("X-Key-Id accepted without being signed")
+	}
+}
+
+func TestVerifyKeyRing(t *testing.T) {
+	ring, err := NewKeyRing("k2", &Key{ID: "k1", Secret: "old"}, &Key{ID: "k2", Secret: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier(KeyRingSecrets{"app": ring})
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	if err = SignRequestKeyRing(r, "app", ring, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 没有 X-Signed-Headers 时密钥 ID 仍然参与签名，替换后校验失败
+	r.Header.Del(HeaderSignedHeaders)
+	r.Header.Set(HeaderKeyID, "k1")
+	if _, err = v.Verify(r); err == nil {
+		t.Fatal("swapped key id accepted")
+	}
+}
+
+func TestMiddlewareHidesReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewVerifier(testSecrets).Middleware())
+	router.GET("/orders", func(c *gin.Context) {
+		result.OkData(c.GetString(ContextAppKey), c)
+	})
+	for _, appKey := range []string{"app", "missing"} {
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		if err := SignRequest(r, appKey, "wrong", nil); err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		var resp result.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		// 应用不存在和密钥错误返回相同的信息
+		if w.Code != http.StatusOK || resp.Code != result.FAIL || resp.Message != MsgVerifyFailed {
+			t.Errorf("%s: status %d, response %+v", appKey, w.Code, resp)
+		}
+	}
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	if err := SignRequest(r, "app", "secret", nil); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), `"content":"app"`) {
+		t.Fatalf("signed request: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,245 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+const (
+
+	/** webhook 签名请求头，格式为 t=1700000000,v1=<hmac>,v1=<旧密钥 hmac> */
+	HeaderWebhookSignature = "X-Webhook-Signature"
+
+	/** 当前签名版本，hmac-sha256 计算 "时间戳.请求体"，hex 编码 */
+	WebhookSchemeV1 = "v1"
+
+	/** 默认允许的时间偏差 */
+	DefaultWebhookTolerance = 5 * time.Minute
+
+	/** 校验失败时返回给调用方的信息，具体原因只写入日志 */
+	MsgWebhookVerifyFailed = "webhook 签名校验失败"
+
+	/** 不校验时间戳，只有能自行防重放时才应使用 */
+	NoWebhookTolerance time.Duration = -1
+)
+
+var ErrInvalidWebhookHeader = errors.New("webhook 签名头格式错误")
+
+// WebhookPayload webhook 签名原文，即 "时间戳.请求体"
+func WebhookPayload(timestamp int64, body []byte) []byte {
+	ts := strconv.FormatInt(timestamp, 10)
+	payload := make([]byte, 0, len(ts)+1+len(body))
+	payload = append(payload, ts...)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+// SignWebhook 生成 webhook 签名头，每个密钥一个 v1 签名，密钥轮换期间同时传入新旧密钥
+func SignWebhook(body []byte, t time.Time, secrets ...string) (string, error) {
+	if len(secrets) == 0 {
+		return "", errors.New("webhook 签名密钥不能为空")
+	}
+	timestamp := t.Unix()
+	payload := WebhookPayload(timestamp, body)
+	var b strings.Builder
+	b.WriteString("t=")
+	b.WriteString(strconv.FormatInt(timestamp, 10))
+	for _, secret := range secrets {
+		sum, err := Hmac(AlgSHA256, payload, []byte(secret))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("," + WebhookSchemeV1 + "=")
+		b.WriteString(hex.EncodeToString(sum))
+	}
+	return b.String(), nil
+}
+
+// SignWebhookKeyRing 使用密钥环中全部有效密钥生成 webhook 签名头，接收方无论加载了新密钥还是旧密钥都能校验
+func SignWebhookKeyRing(ring *KeyRing, body []byte, t time.Time) (string, error) {
+	keys := ring.ValidKeys()
+	secrets := make([]string, 0, len(keys))
+	for _, key := range keys {
+		secrets = append(secrets, key.Secret)
+	}
+	return SignWebhook(body, t, secrets...)
+}
+
+// ParseWebhookHeader 解析 webhook 签名头，返回时间戳和全部 v1 签名，未知版本的签名会被忽略
+func ParseWebhookHeader(header string) (int64, [][]byte, error) {
+	var (
+		timestamp  int64
+		hasTime    bool
+		signatures [][]byte
+	)
+	for _, item := range strings.Split(header, ",") {
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			return 0, nil, ErrInvalidWebhookHeader
+		}
+		key, value := strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		switch key {
+		case "t":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || hasTime {
+				return 0, nil, ErrInvalidWebhookHeader
+			}
+			timestamp, hasTime = ts, true
+		case WebhookSchemeV1:
+			sig, err := hex.DecodeString(value)
+			if err != nil {
+				// 单个签名损坏不影响其余签名
+				continue
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	if !hasTime || len(signatures) == 0 {
+		return 0, nil, ErrInvalidWebhookHeader
+	}
+	return timestamp, signatures, nil
+}
+
+// VerifyWebhook 校验 webhook 签名头，时间偏差超过 tolerance 返回 ErrExpired
+// tolerance 为 0 时使用 DefaultWebhookTolerance，传 NoWebhookTolerance 才不校验时间
+// 头中任一签名与任一密钥匹配即通过
+func VerifyWebhook(body []byte, header string, tolerance time.Duration, secrets ...string) error {
+	return verifyWebhook(body, header, tolerance, time.Now(), secrets)
+}
+
+func verifyWebhook(body []byte, header string, tolerance time.Duration, now time.Time, secrets []string) error {
+	timestamp, signatures, err := ParseWebhookHeader(header)
+	if err != nil {
+		return err
+	}
+	if tolerance == 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	if tolerance > 0 {
+		diff := now.Sub(time.Unix(timestamp, 0))
+		if diff > tolerance || diff < -tolerance {
+			return ErrExpired
+		}
+	}
+	payload := WebhookPayload(timestamp, body)
+	for _, secret := range secrets {
+		expected, err := Hmac(AlgSHA256, payload, []byte(secret))
+		if err != nil {
+			return err
+		}
+		for _, sig := range signatures {
+			if hmac.Equal(expected, sig) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// WebhookVerifier webhook 接收方的签名校验
+type WebhookVerifier struct {
+
+	/** 密钥，轮换期间同时配置新旧密钥 */
+	Secrets []string
+
+	/** 密钥环，设置后与 Secrets 一起使用，其中全部有效密钥都可以校验 */
+	Keys *KeyRing
+
+	/** 签名头，默认 HeaderWebhookSignature */
+	Header string
+
+	/** 允许的时间偏差，默认 DefaultWebhookTolerance，设为 NoWebhookTolerance 时不校验时间 */
+	Tolerance time.Duration
+
+	/** 最大请求体，默认 DefaultMaxBodySize */
+	MaxBodySize int64
+
+	/** 当前时间，默认 time.Now */
+	Now func() time.Time
+}
+
+// NewWebhookVerifier 创建 webhook 校验
+func NewWebhookVerifier(secrets ...string) *WebhookVerifier {
+	return &WebhookVerifier{Secrets: secrets}
+}
+
+// Verify 校验 webhook 请求，成功时返回请求体，并放回 r.Body 供后续读取
+func (w *WebhookVerifier) Verify(r *http.Request) ([]byte, error) {
+	name := w.Header
+	if name == "" {
+		name = HeaderWebhookSignature
+	}
+	header := r.Header.Get(name)
+	if header == "" {
+		return nil, ErrMissingHeader
+	}
+	body, err := readRequestBody(r, w.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	secrets := w.Secrets
+	if w.Keys != nil {
+		secrets = append([]string(nil), w.Secrets...)
+		for _, key := range w.Keys.ValidKeys() {
+			secrets = append(secrets, key.Secret)
+		}
+	}
+	if len(secrets) == 0 {
+		return nil, errors.New("未配置 webhook 密钥")
+	}
+	now := time.Now
+	if w.Now != nil {
+		now = w.Now
+	}
+	if err = verifyWebhook(body, header, w.Tolerance, now(), secrets); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Handler 校验通过后交给 next 处理，next 可以照常读取 r.Body
+// 校验失败时与 result.FailMsg 一致，返回 200 和 code 为 1 的 result.Response，具体原因只写入日志
+func (w *WebhookVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if _, err := w.Verify(r); err != nil {
+			log.Println(MsgWebhookVerifyFailed + "：" + err.Error())
+			rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+			rw.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(rw).Encode(result.Response{
+				Code:    result.FAIL,
+				Content: map[string]interface{}{},
+				Message: MsgWebhookVerifyFailed,
+			})
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Middleware gin 中间件，校验失败时以统一结构返回错误并中止请求
+func (w *WebhookVerifier) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := w.Verify(c.Request); err != nil {
+			log.Println(MsgWebhookVerifyFailed + "：" + err.Error())
+			result.FailMsg(MsgWebhookVerifyFailed, c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,142 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package sign
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+func TestWebhookSignVerify(t *testing.T) {
+	body := []byte(`{"event":"paid"}`)
+	now := time.Now()
+	header, err := SignWebhook(body, now, "new", "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(header, "t=") || strings.Count(header, ",v1=") != 2 {
+		t.Fatalf("header = %s", header)
+	}
+	// 接收方只有其中一个密钥也能校验
+	for _, secret := range []string{"new", "old"} {
+		if err = VerifyWebhook(body, header, 0, secret); err != nil {
+			t.Errorf("secret %s: %v", secret, err)
+		}
+	}
+	if err = VerifyWebhook(body, header, 0, "other"); err != ErrInvalidSignature {
+		t.Fatalf("wrong secret: %v", err)
+	}
+	if err = VerifyWebhook([]byte(`{"event":"refund"}`), header, 0, "new"); err != ErrInvalidSignature {
+		t.Fatalf("tampered body: %v", err)
+	}
+	if _, err = SignWebhook(body, now); err == nil {
+		t.Fatal("signed without secrets")
+	}
+}
+
+func TestWebhookTolerance(t *testing.T) {
+	body := []byte("x")
+	header, _ := SignWebhook(body, time.Now().Add(-10*time.Minute), "s")
+	if err := VerifyWebhook(body, header, 0, "s"); err != ErrExpired {
+		t.Fatalf("old webhook: %v", err)
+	}
+	if err := VerifyWebhook(body, header, time.Hour, "s"); err != nil {
+		t.Fatalf("custom tolerance: %v", err)
+	}
+	if err := VerifyWebhook(body, header, NoWebhookTolerance, "s"); err != nil {
+		t.Fatalf("NoWebhookTolerance: %v", err)
+	}
+}
+
+func TestParseWebhookHeader(t *testing.T) {
+	ts, sigs, err := ParseWebhookHeader("t=1700000000, v1=zz, v0=abcd, v1=abcd")
+	if err != nil || ts != 1700000000 || len(sigs) != 1 {
+		t.Fatalf("ParseWebhookHeader = %d, %x, %v", ts, sigs, err)
+	}
+	for _, header := range []string{"", "t=1", "v1=abcd", "t=x,v1=abcd", "t=1,t=2,v1=abcd", "t=1,v1"} {
+		if _, _, err = ParseWebhookHeader(header); err != ErrInvalidWebhookHeader {
+			t.Errorf("ParseWebhookHeader(%q) err = %v", header, err)
+		}
+	}
+}
+
+func TestWebhookVerifierKeyRing(t *testing.T) {
+	ring, _ := NewKeyRing("k2", &Key{ID: "k1", Secret: "old"}, &Key{ID: "k2", Secret: "new"})
+	body := []byte("payload")
+	header, err := SignWebhookKeyRing(ring, body, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(string(body)))
+	r.Header.Set(HeaderWebhookSignature, header)
+	got, err := (&WebhookVerifier{Secrets: []string{"old"}}).Verify(r)
+	if err != nil || string(got) != "payload" {
+		t.Fatalf("Verify = %q, %v", got, err)
+	}
+	// 请求体放回后仍可读取
+	if rest, _ := io.ReadAll(r.Body); string(rest) != "payload" {
+		t.Fatalf("body after Verify = %q", rest)
+	}
+	if _, err = (&WebhookVerifier{}).Verify(r); err == nil {
+		t.Fatal("verifier without secrets accepted")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	verifier := NewWebhookVerifier("s")
+	handler := verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	body := "payload"
+	header, _ := SignWebhook([]byte(body), time.Now(), "s")
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	r.Header.Set(HeaderWebhookSignature, header)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Body.String() != body {
+		t.Fatalf("next received %q", w.Body.String())
+	}
+
+	// 失败时与 result.FailMsg 相同：200、code 为 1、统一的信息
+	r = httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	var resp result.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || resp.Code != result.FAIL || resp.Message != MsgWebhookVerifyFailed {
+		t.Fatalf("status %d, response %+v", w.Code, resp)
+	}
+}
+
+func TestWebhookMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewWebhookVerifier("s").Middleware())
+	router.POST("/hook", func(c *gin.Context) {
+		result.Ok(c)
+	})
+	header, _ := SignWebhook([]byte("payload"), time.Now(), "s")
+	for sig, want := range map[string]string{header: `"code":"0"`, "t=1,v1=abcd": MsgWebhookVerifyFailed} {
+		r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader("payload"))
+		r.Header.Set(HeaderWebhookSignature, sig)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: status %d, body %s", sig, w.Code, w.Body.String())
+		}
+	}
+}
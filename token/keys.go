@@ -0,0 +1,189 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"sync"
+
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// Key 令牌密钥，HS256 使用 Secret，其余算法使用 PrivateKey 签名、PublicKey 验证
+type Key struct {
+
+	/** 密钥 ID，写入令牌头的 kid */
+	ID string
+
+	/** 算法，HS256、RS256、ES256 或 EdDSA */
+	Algorithm string
+
+	/** HS256 的密钥 */
+	Secret []byte
+
+	/** 签名私钥，只验证令牌时可以为空 */
+	PrivateKey crypto.Signer
+
+	/** 验证公钥，为空时使用 PrivateKey 的公钥 */
+	PublicKey crypto.PublicKey
+}
+
+// check 检查算法和密钥类型是否匹配
+func (k *Key) check() error {
+	if k.Algorithm == HS256 {
+		if len(k.Secret) == 0 {
+			return errors.New("HS256 密钥不能为空")
+		}
+		return nil
+	}
+	pub := k.publicKey()
+	var ok bool
+	switch k.Algorithm {
+	case RS256:
+		_, ok = pub.(*rsa.PublicKey)
+	case ES256:
+		var ec *ecdsa.PublicKey
+		ec, ok = pub.(*ecdsa.PublicKey)
+		ok = ok && ec.Curve == elliptic.P256()
+	case EdDSA:
+		_, ok = pub.(ed25519.PublicKey)
+	default:
+		return errors.New("不支持的令牌算法：" + k.Algorithm)
+	}
+	if !ok {
+		return errors.New("密钥" + k.ID + "的类型与算法" + k.Algorithm + "不匹配")
+	}
+	return nil
+}
+
+func (k *Key) publicKey() crypto.PublicKey {
+	if k.PublicKey != nil {
+		return k.PublicKey
+	}
+	if k.PrivateKey != nil {
+		return k.PrivateKey.Public()
+	}
+	return nil
+}
+
+// KeySource 令牌密钥来源，签名使用当前密钥，验证时按令牌头的 kid 查找密钥
+type KeySource interface {
+
+	// SigningKey 签名使用的当前密钥
+	SigningKey() (*Key, error)
+
+	// VerifyingKey 按 kid 查找验证密钥，kid 为空表示令牌没有 kid
+	VerifyingKey(kid string) (*Key, error)
+}
+
+// KeySet 内存中的密钥集合，支持轮换：加入新密钥后切换当前密钥，旧密钥保留到旧令牌全部过期
+type KeySet struct {
+	mu     sync.RWMutex
+	active string
+	keys   map[string]*Key
+}
+
+// NewKeySet 创建密钥集合，active 为签名使用的密钥 ID
+func NewKeySet(active string, keys ...*Key) (*KeySet, error) {
+	s := &KeySet{}
+	if err := s.Replace(active, keys...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Replace 原子地替换全部密钥
+func (s *KeySet) Replace(active string, keys ...*Key) error {
+	m := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		if err := key.check(); err != nil {
+			return err
+		}
+		if m[key.ID] != nil {
+			return errors.New("密钥 ID 重复：" + key.ID)
+		}
+		m[key.ID] = key
+	}
+	if active != "" && m[active] == nil {
+		return errors.New("当前密钥" + active + "不在密钥集合中")
+	}
+	s.mu.Lock()
+	s.active, s.keys = active, m
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *KeySet) SigningKey() (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := s.keys[s.active]
+	if key == nil || (key.Algorithm != HS256 && key.PrivateKey == nil) {
+		return nil, errors.New("没有可用于签名的密钥")
+	}
+	return key, nil
+}
+
+func (s *KeySet) VerifyingKey(kid string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	key := s.keys[kid]
+	if key == nil {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// StaticKey 只有一个密钥的 KeySource
+func StaticKey(key *Key) (KeySource, error) {
+	if key == nil {
+		return nil, errors.New("密钥不能为空")
+	}
+	return NewKeySet(key.ID, key)
+}
+
+// HMACKeyRing 使用 sign.KeyRing 的 HS256 密钥来源，与请求签名共用同一套密钥轮换
+type HMACKeyRing struct {
+	Ring *sign.KeyRing
+}
+
+func (h HMACKeyRing) SigningKey() (*Key, error) {
+	key, err := h.Ring.Active()
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: key.ID, Algorithm: HS256, Secret: []byte(key.Secret)}, nil
+}
+
+func (h HMACKeyRing) VerifyingKey(kid string) (*Key, error) {
+	if kid == "" {
+		return nil, ErrKeyNotFound
+	}
+	key, err := h.Ring.Get(kid)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: key.ID, Algorithm: HS256, Secret: []byte(key.Secret)}, nil
+}
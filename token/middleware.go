@@ -0,0 +1,62 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package token
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+)
+
+const (
+
+	/** 令牌请求头 */
+	HeaderAuthorization = "Authorization"
+
+	/** 上下文中保存声明的键 */
+	ContextClaims = "tokenClaims"
+)
+
+// FromRequest 从 Authorization: Bearer xxx 中取出令牌
+func FromRequest(c *gin.Context) (string, error) {
+	auth := strings.TrimSpace(c.GetHeader(HeaderAuthorization))
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		if tokenString := strings.TrimSpace(auth[7:]); tokenString != "" {
+			return tokenString, nil
+		}
+	}
+	return "", ErrMissingToken
+}
+
+// Middleware gin 中间件，newClaims 返回用于解析的空声明，校验失败时以统一结构返回错误并中止请求
+//
+//	r.Use(j.Middleware(func() token.Claims { return &UserClaims{} }))
+func (j *JWT) Middleware(newClaims func() Claims) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, err := FromRequest(c)
+		if err == nil {
+			claims := newClaims()
+			if err = j.Parse(tokenString, claims); err == nil {
+				c.Set(ContextClaims, claims)
+				c.Next()
+				return
+			}
+		}
+		result.FailMsg("身份认证失败："+err.Error(), c)
+		c.Abort()
+	}
+}
+
+// GetClaims 取出中间件保存的声明，需要断言为 newClaims 返回的类型
+func GetClaims(c *gin.Context) (Claims, bool) {
+	v, ok := c.Get(ContextClaims)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(Claims)
+	return claims, ok
+}
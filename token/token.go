@@ -0,0 +1,335 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package token
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+const (
+
+	/** 默认有效期 */
+	DefaultTTL = 2 * time.Hour
+)
+
+var (
+	ErrMalformed    = errors.New("令牌格式错误")
+	ErrAlgorithm    = errors.New("令牌算法与密钥不匹配")
+	ErrSignature    = errors.New("令牌签名错误")
+	ErrExpired      = errors.New("令牌已过期")
+	ErrMissingExp   = errors.New("令牌缺少过期时间")
+	ErrNotValidYet  = errors.New("令牌尚未生效")
+	ErrIssuer       = errors.New("令牌签发者不匹配")
+	ErrAudience     = errors.New("令牌接收方不匹配")
+	ErrKeyNotFound  = errors.New("令牌密钥不存在")
+	ErrMissingToken = errors.New("缺少令牌")
+)
+
+// Claims 令牌声明，自定义声明内嵌 StandardClaims 即可实现
+//
+//	type UserClaims struct {
+//		token.StandardClaims
+//		UserID string `json:"userId"`
+//	}
+type Claims interface {
+	Standard() *StandardClaims
+}
+
+// StandardClaims 标准声明，时间为 unix 秒
+type StandardClaims struct {
+
+	/** 签发者 */
+	Issuer string `json:"iss,omitempty"`
+
+	/** 主题，一般为用户 ID */
+	Subject string `json:"sub,omitempty"`
+
+	/** 接收方 */
+	Audience Audience `json:"aud,omitempty"`
+
+	/** 过期时间 */
+	ExpiresAt int64 `json:"exp,omitempty"`
+
+	/** 生效时间 */
+	NotBefore int64 `json:"nbf,omitempty"`
+
+	/** 签发时间 */
+	IssuedAt int64 `json:"iat,omitempty"`
+
+	/** 令牌 ID */
+	ID string `json:"jti,omitempty"`
+}
+
+func (c *StandardClaims) Standard() *StandardClaims {
+	return c
+}
+
+// Audience 接收方，只有一个时序列化为字符串，解析时兼容字符串和数组
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*a = multi
+	return nil
+}
+
+// Contains 是否包含 aud
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// header 令牌头
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// JWT 令牌签发和验证
+type JWT struct {
+
+	/** 密钥来源 */
+	Keys KeySource
+
+	/** 签发者，签发时写入 iss，验证时要求一致，为空时不校验 */
+	Issuer string
+
+	/** 接收方，签发时写入 aud，验证时要求令牌包含其中之一，为空时不校验 */
+	Audience []string
+
+	/** 有效期，签发时 exp 为空则使用该值，默认 DefaultTTL */
+	TTL time.Duration
+
+	/** 验证 exp 和 nbf 时允许的时钟偏差 */
+	Leeway time.Duration
+
+	/** 是否接受没有 exp 的令牌，默认拒绝，这样的令牌一旦泄露就永久有效 */
+	AllowMissingExp bool
+
+	/** 当前时间，默认 time.Now */
+	Now func() time.Time
+}
+
+// New 创建令牌签发和验证
+func New(keys KeySource) *JWT {
+	return &JWT{Keys: keys, TTL: DefaultTTL}
+}
+
+func (j *JWT) now() time.Time {
+	if j.Now != nil {
+		return j.Now()
+	}
+	return time.Now()
+}
+
+// Sign 签发令牌，iat 以及为空的 iss、aud、exp 会被自动填充，claims 会被修改
+func (j *JWT) Sign(claims Claims) (string, error) {
+	key, err := j.Keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	std := claims.Standard()
+	now := j.now()
+	std.IssuedAt = now.Unix()
+	if std.Issuer == "" {
+		std.Issuer = j.Issuer
+	}
+	if len(std.Audience) == 0 && len(j.Audience) > 0 {
+		std.Audience = append(Audience{}, j.Audience...)
+	}
+	if std.ExpiresAt == 0 {
+		ttl := j.TTL
+		if ttl <= 0 {
+			ttl = DefaultTTL
+		}
+		std.ExpiresAt = now.Add(ttl).Unix()
+	}
+	h, err := json.Marshal(header{Alg: key.Algorithm, Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := encode(h) + "." + encode(payload)
+	sig, err := signInput(key, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + encode(sig), nil
+}
+
+// Parse 验证令牌签名和标准声明，并解析到 claims
+func (j *JWT) Parse(tokenString string, claims Claims) error {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return ErrMalformed
+	}
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return ErrMalformed
+	}
+	key, err := j.Keys.VerifyingKey(h.Kid)
+	if err != nil {
+		return err
+	}
+	// 算法必须与密钥一致，防止 none 以及用公钥当作 HS256 密钥的算法混淆攻击
+	if h.Alg != key.Algorithm {
+		return ErrAlgorithm
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrMalformed
+	}
+	if err = verifyInput(key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return err
+	}
+	if err = decodeJSON(parts[1], claims); err != nil {
+		return ErrMalformed
+	}
+	return j.Validate(claims.Standard())
+}
+
+// Validate 校验 exp、nbf、iss 和 aud，没有 exp 时返回 ErrMissingExp，除非设置了 AllowMissingExp
+func (j *JWT) Validate(c *StandardClaims) error {
+	now := j.now()
+	if c.ExpiresAt == 0 && !j.AllowMissingExp {
+		return ErrMissingExp
+	}
+	if c.ExpiresAt != 0 && now.After(time.Unix(c.ExpiresAt, 0).Add(j.Leeway)) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Add(j.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrNotValidYet
+	}
+	if j.Issuer != "" && c.Issuer != j.Issuer {
+		return ErrIssuer
+	}
+	if len(j.Audience) > 0 {
+		ok := false
+		for _, aud := range j.Audience {
+			if c.Audience.Contains(aud) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return ErrAudience
+		}
+	}
+	return nil
+}
+
+func signInput(key *Key, input []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case HS256:
+		return sign.Hmac(sign.AlgSHA256, input, key.Secret)
+	case RS256:
+		return sign.SignMessage(sign.RSASHA256, key.PrivateKey, input)
+	case EdDSA:
+		return sign.SignMessage(sign.Ed25519, key.PrivateKey, input)
+	case ES256:
+		// JWT 的 ES256 签名是定长的 r||s，不是 ASN.1
+		priv, ok := key.PrivateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, ErrAlgorithm
+		}
+		sum := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, sum[:])
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	}
+	return nil, errors.New("不支持的令牌算法：" + key.Algorithm)
+}
+
+func verifyInput(key *Key, input, sig []byte) error {
+	switch key.Algorithm {
+	case HS256:
+		expected, err := sign.Hmac(sign.AlgSHA256, input, key.Secret)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(expected, sig) {
+			return ErrSignature
+		}
+		return nil
+	case RS256, EdDSA:
+		scheme := sign.RSASHA256
+		if key.Algorithm == EdDSA {
+			scheme = sign.Ed25519
+		}
+		if err := sign.VerifyMessage(scheme, key.publicKey(), input, sig); err != nil {
+			return ErrSignature
+		}
+		return nil
+	case ES256:
+		pub, ok := key.publicKey().(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return ErrSignature
+		}
+		sum := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, sum[:], r, s) {
+			return ErrSignature
+		}
+		return nil
+	}
+	return errors.New("不支持的令牌算法：" + key.Algorithm)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeJSON 解析 base64url 编码的 json，数字保留为 json.Number 以免自定义声明中的大整数丢失精度
+func decodeJSON(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
@@ -0,0 +1,348 @@
+/**
+ * @Time: 2026/10/19 18:00
+ * @Author: agent
+ */
+
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goworkeryyt/go-toolbox/result"
+	"github.com/goworkeryyt/go-toolbox/sign"
+)
+
+type userClaims struct {
+	StandardClaims
+	UserID json.Number `json:"userId"`
+}
+
+var testNow = time.Unix(1700000000, 0)
+
+// newTestJWT 使用固定时钟的 HS256 令牌
+func newTestJWT(t *testing.T) *JWT {
+	t.Helper()
+	keys, err := StaticKey(&Key{ID: "k1", Algorithm: HS256, Secret: []byte("secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := New(keys)
+	j.Issuer = "toolbox"
+	j.Audience = []string{"web"}
+	j.Now = func() time.Time { return testNow }
+	return j
+}
+
+// signRaw 直接用 HS256 签名任意的头和声明，用于构造 Sign 不会生成的令牌
+func signRaw(t *testing.T, h header, claims interface{}, secret string) string {
+	t.Helper()
+	hb, _ := json.Marshal(h)
+	cb, _ := json.Marshal(claims)
+	input := encode(hb) + "." + encode(cb)
+	sig, err := sign.Hmac(sign.AlgSHA256, []byte(input), []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + encode(sig)
+}
+
+func TestSignParseRoundTrip(t *testing.T) {
+	j := newTestJWT(t)
+	tokenString, err := j.Sign(&userClaims{StandardClaims: StandardClaims{Subject: "u1"}, UserID: "9007199254740993"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims userClaims
+	if err = j.Parse(tokenString, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "u1" || claims.Issuer != "toolbox" || !claims.Audience.Contains("web") {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if claims.IssuedAt != testNow.Unix() || claims.ExpiresAt != testNow.Add(DefaultTTL).Unix() {
+		t.Fatalf("iat/exp = %d/%d", claims.IssuedAt, claims.ExpiresAt)
+	}
+	// 大整数不丢失精度
+	if claims.UserID != "9007199254740993" {
+		t.Fatalf("userId = %s", claims.UserID)
+	}
+	var h header
+	_ = decodeJSON(strings.Split(tokenString, ".")[0], &h)
+	if h.Alg != HS256 || h.Kid != "k1" || h.Typ != "JWT" {
+		t.Fatalf("header = %+v", h)
+	}
+}
+
+func TestValidateTime(t *testing.T) {
+	j := newTestJWT(t)
+	tokenString, _ := j.Sign(&StandardClaims{ExpiresAt: testNow.Add(time.Minute).Unix(), NotBefore: testNow.Add(-time.Minute).Unix()})
+	tests := []struct {
+		now    time.Time
+		leeway time.Duration
+		want   error
+	}{
+		{testNow, 0, nil},
+		{testNow.Add(2 * time.Minute), 0, ErrExpired},
+		{testNow.Add(2 * time.Minute), 2 * time.Minute, nil},
+		{testNow.Add(-2 * time.Minute), 0, ErrNotValidYet},
+		{testNow.Add(-2 * time.Minute), 2 * time.Minute, nil},
+	}
+	for _, tt := range tests {
+		now := tt.now
+		j.Now = func() time.Time { return now }
+		j.Leeway = tt.leeway
+		if err := j.Parse(tokenString, &StandardClaims{}); err != tt.want {
+			t.Errorf("now %v leeway %v: err = %v, want %v", now.Sub(testNow), tt.leeway, err, tt.want)
+		}
+	}
+}
+
+func TestValidateMissingExp(t *testing.T) {
+	j := newTestJWT(t)
+	tokenString := signRaw(t, header{Alg: HS256, Kid: "k1"}, StandardClaims{Issuer: "toolbox", Audience: Audience{"web"}}, "secret")
+	if err := j.Parse(tokenString, &StandardClaims{}); err != ErrMissingExp {
+		t.Fatalf("token without exp: err = %v", err)
+	}
+	j.AllowMissingExp = true
+	if err := j.Parse(tokenString, &StandardClaims{}); err != nil {
+		t.Fatalf("AllowMissingExp: err = %v", err)
+	}
+}
+
+func TestValidateIssuerAudience(t *testing.T) {
+	j := newTestJWT(t)
+	exp := testNow.Add(time.Hour).Unix()
+	tests := []struct {
+		claims StandardClaims
+		want   error
+	}{
+		{StandardClaims{Issuer: "toolbox", Audience: Audience{"app", "web"}, ExpiresAt: exp}, nil},
+		{StandardClaims{Issuer: "other", Audience: Audience{"web"}, ExpiresAt: exp}, ErrIssuer},
+		{StandardClaims{Issuer: "toolbox", Audience: Audience{"app"}, ExpiresAt: exp}, ErrAudience},
+		{StandardClaims{Issuer: "toolbox", ExpiresAt: exp}, ErrAudience},
+	}
+	for _, tt := range tests {
+		tokenString := signRaw(t, header{Alg: HS256, Kid: "k1"}, tt.claims, "secret")
+		if err := j.Parse(tokenString, &StandardClaims{}); err != tt.want {
+			t.Errorf("claims %+v: err = %v, want %v", tt.claims, err, tt.want)
+		}
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	single, _ := json.Marshal(Audience{"web"})
+	multi, _ := json.Marshal(Audience{"web", "app"})
+	if string(single) != `"web"` || string(multi) != `["web","app"]` {
+		t.Fatalf("marshal = %s, %s", single, multi)
+	}
+	var a Audience
+	if err := json.Unmarshal([]byte(`["x","y"]`), &a); err != nil || len(a) != 2 {
+		t.Fatalf("unmarshal array = %v, %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(`"x"`), &a); err != nil || len(a) != 1 || a[0] != "x" {
+		t.Fatalf("unmarshal string = %v, %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(`1`), &a); err == nil {
+		t.Fatal("number accepted as audience")
+	}
+}
+
+func TestParseTampered(t *testing.T) {
+	j := newTestJWT(t)
+	tokenString, _ := j.Sign(&StandardClaims{Subject: "u1"})
+	parts := strings.Split(tokenString, ".")
+	forged, _ := json.Marshal(StandardClaims{Subject: "admin", Issuer: "toolbox", Audience: Audience{"web"}, ExpiresAt: testNow.Add(time.Hour).Unix()})
+	tests := map[string]error{
+		"a.b":                             ErrMalformed,
+		"!!." + parts[1] + "." + parts[2]: ErrMalformed,
+		parts[0] + "." + parts[1] + ".!!": ErrMalformed,
+		parts[0] + "." + encode(forged) + "." + parts[2]:                      ErrSignature,
+		signRaw(t, header{Alg: HS256, Kid: "k1"}, StandardClaims{}, "other"):  ErrSignature,
+		signRaw(t, header{Alg: HS256, Kid: "k9"}, StandardClaims{}, "secret"): ErrKeyNotFound,
+	}
+	for tokenString, want := range tests {
+		if err := j.Parse(tokenString, &StandardClaims{}); err != want {
+			t.Errorf("Parse(%.40s) err = %v, want %v", tokenString, err, want)
+		}
+	}
+}
+
+// 令牌头的 alg 必须与密钥的算法一致
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, _ := StaticKey(&Key{ID: "k1", Algorithm: RS256, PublicKey: &rsaKey.PublicKey})
+	j := New(keys)
+	j.Now = func() time.Time { return testNow }
+	claims := StandardClaims{ExpiresAt: testNow.Add(time.Hour).Unix()}
+
+	// 用公钥当作 HS256 的密钥签名
+	pubDER, _ := json.Marshal(rsaKey.PublicKey)
+	if err = j.Parse(signRaw(t, header{Alg: HS256, Kid: "k1"}, claims, string(pubDER)), &StandardClaims{}); err != ErrAlgorithm {
+		t.Fatalf("HS256 with public key: err = %v", err)
+	}
+	// alg 为 none 且没有签名
+	hb, _ := json.Marshal(header{Alg: "none", Kid: "k1"})
+	cb, _ := json.Marshal(claims)
+	if err = j.Parse(encode(hb)+"."+encode(cb)+".", &StandardClaims{}); err != ErrAlgorithm {
+		t.Fatalf("alg none: err = %v", err)
+	}
+}
+
+func TestAsymmetricAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	for _, key := range []*Key{
+		{ID: "rs", Algorithm: RS256, PrivateKey: rsaKey},
+		{ID: "es", Algorithm: ES256, PrivateKey: ecKey},
+		{ID: "ed", Algorithm: EdDSA, PrivateKey: edKey},
+	} {
+		signer, err := StaticKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 验证方只持有公钥
+		verifier, err := StaticKey(&Key{ID: key.ID, Algorithm: key.Algorithm, PublicKey: key.PrivateKey.Public()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokenString, err := New(signer).Sign(&StandardClaims{Subject: "u1"})
+		if err != nil {
+			t.Fatalf("%s: %v", key.Algorithm, err)
+		}
+		var claims StandardClaims
+		if err = New(verifier).Parse(tokenString, &claims); err != nil || claims.Subject != "u1" {
+			t.Errorf("%s: Parse = %v", key.Algorithm, err)
+		}
+		if _, err = New(verifier).Sign(&StandardClaims{}); err == nil {
+			t.Errorf("%s: signed without a private key", key.Algorithm)
+		}
+	}
+}
+
+// ES256 的签名是 32 字节 r 和 32 字节 s 拼接，不是 ASN.1
+func TestES256RawSignature(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys, _ := StaticKey(&Key{ID: "es", Algorithm: ES256, PrivateKey: ecKey})
+	j := New(keys)
+	tokenString, _ := j.Sign(&StandardClaims{})
+	parts := strings.Split(tokenString, ".")
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if len(sig) != 64 {
+		t.Fatalf("signature length = %d", len(sig))
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&ecKey.PublicKey, sum[:], r, s) {
+		t.Fatal("signature is not r||s")
+	}
+	// ASN.1 格式的签名被拒绝
+	asn1Sig, _ := ecdsa.SignASN1(rand.Reader, ecKey, sum[:])
+	if err := j.Parse(parts[0]+"."+parts[1]+"."+encode(asn1Sig), &StandardClaims{}); err != ErrSignature {
+		t.Fatalf("ASN.1 signature: err = %v", err)
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	if _, err := StaticKey(nil); err == nil {
+		t.Fatal("StaticKey(nil) accepted")
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	bad := []*Key{
+		{ID: "k", Algorithm: HS256},
+		{ID: "k", Algorithm: ES256, PrivateKey: ecKey},
+		{ID: "k", Algorithm: RS256, Secret: []byte("s")},
+		{ID: "k", Algorithm: "PS256", Secret: []byte("s")},
+	}
+	for _, key := range bad {
+		if _, err := StaticKey(key); err == nil {
+			t.Errorf("key %s/%s accepted", key.Algorithm, key.ID)
+		}
+	}
+	old := &Key{ID: "k1", Algorithm: HS256, Secret: []byte("old")}
+	set, err := NewKeySet("k1", old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := New(set)
+	oldToken, _ := j.Sign(&StandardClaims{})
+	// 轮换后旧令牌仍按 kid 验证，新令牌使用新密钥
+	if err = set.Replace("k2", old, &Key{ID: "k2", Algorithm: HS256, Secret: []byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+	newToken, _ := j.Sign(&StandardClaims{})
+	for _, tokenString := range []string{oldToken, newToken} {
+		if err = j.Parse(tokenString, &StandardClaims{}); err != nil {
+			t.Errorf("Parse after rotation: %v", err)
+		}
+	}
+	if err = set.Replace("k3", old); err == nil {
+		t.Fatal("missing active key accepted")
+	}
+}
+
+func TestHMACKeyRing(t *testing.T) {
+	ring, err := sign.NewKeyRing("k1", &sign.Key{ID: "k1", Secret: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := New(HMACKeyRing{Ring: ring})
+	tokenString, err := j.Sign(&StandardClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Parse(tokenString, &StandardClaims{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Parse(signRaw(t, header{Alg: HS256}, StandardClaims{}, "s1"), &StandardClaims{}); err != ErrKeyNotFound {
+		t.Fatalf("token without kid: err = %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := newTestJWT(t)
+	router := gin.New()
+	router.Use(j.Middleware(func() Claims { return &userClaims{} }))
+	router.GET("/me", func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			result.Fail(c)
+			return
+		}
+		result.OkData(claims.(*userClaims).UserID, c)
+	})
+	tokenString, _ := j.Sign(&userClaims{UserID: "42"})
+	for auth, want := range map[string]string{
+		"Bearer " + tokenString: `"content":42`,
+		"bearer " + tokenString: `"content":42`,
+		"":                      ErrMissingToken.Error(),
+		"Basic abc":             ErrMissingToken.Error(),
+		"Bearer a.b.c":          ErrMalformed.Error(),
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		r.Header.Set(HeaderAuthorization, auth)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%q: response = %s", auth, w.Body.String())
+		}
+	}
+}